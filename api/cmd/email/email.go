@@ -14,6 +14,11 @@ var EmailCommand = &cobra.Command{
 			Domain: notifyStock.Cfg.MailDomain,
 			ApiKey: notifyStock.Cfg.MailGunAPIKey,
 		})
-		c.Send(notifyStock.Cfg.FROM, notifyStock.Cfg.TO, "test mail", "hello")
+		c.Send(notifyStock.Mail{
+			From:    notifyStock.Cfg.FROM,
+			To:      notifyStock.Cfg.TO,
+			Subject: "test mail",
+			Text:    "hello",
+		})
 	},
 }
@@ -1,8 +1,8 @@
 package fetch
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
@@ -14,7 +14,7 @@ var FetchCommand = &cobra.Command{
 	Use:   "fetch",
 	Short: "Fetch stock data",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := fetch(symbol); err != nil {
+		if err := fetch(cmd.Context(), symbol); err != nil {
 			fmt.Println("Error fetching stock data:", err)
 			return
 		}
@@ -28,11 +28,9 @@ func init() {
 	FetchCommand.Flags().StringVarP(&symbol, "symbol", "s", "", "Stock symbol to fetch data for")
 }
 
-func fetch(symbol string) error {
-	client := notify.NewFinanceClient(&http.Client{})
-	// res, err := client.FetchCurrentStock(symbol)
-	// res, err := client.FetchStock(symbol, time.Now().AddDate(0, 0, -7), time.Now(), notify.WithInterval("1d"))
-	res, err := client.FetchStock(symbol, time.Now().AddDate(0, 0, -7), time.Now())
+func fetch(ctx context.Context, symbol string) error {
+	client := notify.NewMarketDataProvider(notify.NewHTTPClient())
+	res, err := client.FetchStock(ctx, symbol, time.Now().AddDate(0, 0, -7), time.Now())
 	if err != nil {
 		return err
 	}
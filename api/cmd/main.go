@@ -9,6 +9,7 @@ import (
 	"github.com/heyjun3/notify-stock/cmd/notify"
 	"github.com/heyjun3/notify-stock/cmd/server"
 	"github.com/heyjun3/notify-stock/cmd/stock"
+	"github.com/heyjun3/notify-stock/cmd/symbol"
 	"github.com/heyjun3/notify-stock/cmd/version"
 	"github.com/heyjun3/notify-stock/cmd/yaml"
 )
@@ -29,6 +30,7 @@ func init() {
 		fetch.FetchCommand,
 		yaml.YamlCommand,
 		stock.Command,
+		symbol.Command,
 		logger.Command,
 	)
 }
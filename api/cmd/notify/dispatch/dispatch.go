@@ -0,0 +1,32 @@
+package dispatch
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/heyjun3/notify-stock/cmd/notify/outbox"
+	notifyapp "github.com/heyjun3/notify-stock/internal"
+)
+
+var Command = &cobra.Command{
+	Use:   "dispatch",
+	Short: "Deliver member notifications scheduled for the current hour",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		dispatcher, err := notifyapp.InitNotificationDispatcher(
+			ctx,
+			notifyapp.NewDB(notifyapp.Cfg.DBDSN),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := dispatcher.Dispatch(ctx, time.Now()); err != nil {
+			log.Fatal(err)
+		}
+		if err := outbox.Drain(cmd); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/heyjun3/notify-stock/cmd/notify/dispatch"
+	"github.com/heyjun3/notify-stock/cmd/notify/outbox"
 	notifyapp "github.com/heyjun3/notify-stock/internal"
 )
 
@@ -14,6 +16,10 @@ var NotifyCommand = &cobra.Command{
 	Short: "Stock summary notification for yesterday",
 	Run: func(cmd *cobra.Command, args []string) {
 		notifyStock(symbols)
+		// failed sends stay in the outbox and are retried by `notify outbox`
+		if err := outbox.Drain(cmd); err != nil {
+			log.Println(err)
+		}
 	},
 }
 
@@ -21,6 +27,10 @@ var symbols []string
 
 func init() {
 	NotifyCommand.Flags().StringSliceVarP(&symbols, "symbol", "s", []string{}, "array of symbol")
+	NotifyCommand.AddCommand(
+		dispatch.Command,
+		outbox.Command,
+	)
 }
 
 func notifyStock(symbols []string) {
@@ -28,10 +38,6 @@ func notifyStock(symbols []string) {
 	notifier, err := notifyapp.InitStockNotifier(
 		context.Background(),
 		db,
-		notifyapp.MailGunClientConfig{
-			Domain: notifyapp.Cfg.MailDomain,
-			ApiKey: notifyapp.Cfg.MailGunAPIKey,
-		},
 	)
 	if err != nil {
 		log.Fatal(err)
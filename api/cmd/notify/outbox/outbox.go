@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	notifyapp "github.com/heyjun3/notify-stock/internal"
+)
+
+var Command = &cobra.Command{
+	Use:   "outbox",
+	Short: "Send queued mails that are due",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := Drain(cmd); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func Drain(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	worker, err := notifyapp.InitOutboxWorker(
+		ctx,
+		notifyapp.NewDB(notifyapp.Cfg.DBDSN),
+		notifyapp.MailGunClientConfig{
+			Domain: notifyapp.Cfg.MailDomain,
+			ApiKey: notifyapp.Cfg.MailGunAPIKey,
+		},
+		notifyapp.NewChannelHTTPClient(10*time.Second),
+		notifyapp.WebhookChannelOption{Secret: notifyapp.Cfg.WebhookSecret},
+		notifyapp.DefaultOutboxWorkerOption(),
+	)
+	if err != nil {
+		return err
+	}
+	attempted, err := worker.Drain(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("attempted %d outbox messages", attempted)
+	return nil
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"net/http"
@@ -27,10 +28,18 @@ var ServerCommand = &cobra.Command{
 		runServer()
 	},
 }
-var isTLS bool
+var (
+	isTLS             bool
+	withScheduler     bool
+	schedulerInterval time.Duration
+)
 
 func init() {
 	ServerCommand.Flags().BoolVar(&isTLS, "tls", false, "Run server with TLS")
+	ServerCommand.Flags().BoolVar(&withScheduler, "scheduler", false,
+		"Dispatch member notifications in process")
+	ServerCommand.Flags().DurationVar(&schedulerInterval, "scheduler-interval", 5*time.Minute,
+		"Interval between notification dispatches")
 }
 
 const defaultPort = "8080"
@@ -91,6 +100,37 @@ func runServer() {
 		}
 		logger.Info("Done ping database")
 	}()
+	if withScheduler {
+		dispatcher, err := notifystock.InitNotificationDispatcher(
+			context.Background(),
+			db,
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		worker, err := notifystock.InitOutboxWorker(
+			context.Background(),
+			db,
+			notifystock.MailGunClientConfig{
+				Domain: notifystock.Cfg.MailDomain,
+				ApiKey: notifystock.Cfg.MailGunAPIKey,
+			},
+			notifystock.NewChannelHTTPClient(10*time.Second),
+			notifystock.WebhookChannelOption{Secret: notifystock.Cfg.WebhookSecret},
+			notifystock.DefaultOutboxWorkerOption(),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go func() {
+			logger.Info("Start notification scheduler", "interval", schedulerInterval)
+			dispatcher.Run(context.Background(), schedulerInterval)
+		}()
+		go func() {
+			logger.Info("Start outbox worker", "interval", schedulerInterval)
+			worker.Run(context.Background(), schedulerInterval)
+		}()
+	}
 	sessionRepo := notifystock.NewSessionRepository(db)
 	sessions := notifystock.InitSessionsWithRepo(sessionRepo)
 	authHandler := notifystock.InitAuthHandler(
@@ -105,7 +145,7 @@ func runServer() {
 	)
 
 	resolver := graph.InitResolver(db)
-	directives := graph.InitRootDirective(logger)
+	directives := graph.InitRootDirective(logger, db)
 	c := graph.Config{
 		Resolvers:  resolver,
 		Directives: *directives,
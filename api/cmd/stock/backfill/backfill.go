@@ -0,0 +1,84 @@
+package backfill
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func init() {
+	Command.Flags().StringVar(&from, "from", "",
+		"first date to register (YYYY-MM-DD), defaults to the first trade date of each symbol")
+	Command.Flags().StringVar(&to, "to", "", "last date to register (YYYY-MM-DD), defaults to now")
+	Command.Flags().StringSliceVarP(&symbols, "symbol", "s", nil,
+		"symbols to backfill, defaults to the tracked symbols")
+	Command.Flags().IntVar(&chunkYears, "chunk", notify.DefaultBackfillOption().ChunkYears,
+		"years of prices fetched at a time")
+}
+
+var (
+	from       string
+	to         string
+	symbols    []string
+	chunkYears int
+	Command    = &cobra.Command{
+		Use:   "backfill",
+		Short: "Register the history of symbols in chunks, resuming interrupted runs",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+			start, err := parseDate(from, time.Time{})
+			if err != nil {
+				panic(err)
+			}
+			end := time.Now()
+			if to != "" {
+				last, err := parseDate(to, time.Time{})
+				if err != nil {
+					panic(err)
+				}
+				// the end of the period is not included
+				end = last.AddDate(0, 0, 1)
+			}
+			db := notify.NewDB(notify.Cfg.DBDSN)
+			if len(symbols) == 0 {
+				symbols, err = notify.InitTrackedSymbolRepository(db).EnabledSymbols(ctx)
+				if err != nil {
+					panic(err)
+				}
+			}
+			option := notify.DefaultBackfillOption()
+			option.ChunkYears = chunkYears
+			backfiller := notify.InitBackfiller(
+				db,
+				notify.NewHTTPClient(),
+				notify.DefaultStockRegisterOption(),
+				option,
+			)
+			backfillErr := backfiller.Backfill(ctx, symbols, start, end)
+			repository := notify.InitBackfillJobRepository(db)
+			for _, symbol := range symbols {
+				jobs, err := repository.GetBySymbol(ctx, symbol)
+				if err != nil || len(jobs) == 0 {
+					continue
+				}
+				job := jobs[0]
+				log.Printf("%s: %s, %d chunks registered until %s",
+					symbol, job.Status, job.Chunks, job.Cursor.Format(time.DateOnly))
+			}
+			if backfillErr != nil {
+				log.Fatalf("failed to backfill %v, run the command again to resume: %v",
+					notify.FailedSymbols(backfillErr), backfillErr)
+			}
+		},
+	}
+)
+
+func parseDate(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,47 @@
+package intraday
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func init() {
+	Command.Flags().StringVarP(&interval, "interval", "i", string(notify.IntervalFiveMinutes),
+		"bar interval: one_minute, five_minutes or one_hour")
+}
+
+var (
+	interval string
+	Command  = &cobra.Command{
+		Use:   "intraday",
+		Short: "Register intraday prices and prune the expired ones",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+			db := notify.NewDB(notify.Cfg.DBDSN)
+			symbols, err := notify.InitTrackedSymbolRepository(db).EnabledSymbols(ctx)
+			if err != nil {
+				panic(err)
+			}
+			register := notify.InitIntradayRegister(
+				db,
+				notify.NewHTTPClient(),
+			)
+			now := time.Now()
+			registerErr := register.Register(ctx, symbols, notify.Interval(interval), now)
+			deleted, err := register.Prune(ctx, now)
+			if err != nil {
+				log.Println(err)
+			} else {
+				log.Printf("pruned %d intraday prices", deleted)
+			}
+			if registerErr != nil {
+				log.Printf("failed to register %v", notify.FailedSymbols(registerErr))
+				panic(registerErr)
+			}
+		},
+	}
+)
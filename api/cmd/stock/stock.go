@@ -3,7 +3,10 @@ package stock
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/heyjun3/notify-stock/cmd/stock/backfill"
+	"github.com/heyjun3/notify-stock/cmd/stock/intraday"
 	"github.com/heyjun3/notify-stock/cmd/stock/update"
+	"github.com/heyjun3/notify-stock/cmd/stock/verify"
 )
 
 var (
@@ -16,5 +19,8 @@ var (
 func init() {
 	Command.AddCommand(
 		update.StockCommand,
+		intraday.Command,
+		verify.Command,
+		backfill.Command,
 	)
 }
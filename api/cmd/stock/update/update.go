@@ -1,9 +1,12 @@
 package update
 
 import (
-	"net/http"
+	"log"
 	"time"
 
+	"github.com/uptrace/bun"
+
+	"github.com/heyjun3/notify-stock/cmd/notify/outbox"
 	notify "github.com/heyjun3/notify-stock/internal"
 	"github.com/spf13/cobra"
 )
@@ -11,36 +14,73 @@ import (
 func init() {
 	StockCommand.Flags().BoolVarP(&isAll, "all", "a", false,
 		"register stock price data for the entire period")
+	StockCommand.Flags().IntVarP(&days, "days", "d", 5,
+		"number of trading days to register")
+	StockCommand.Flags().IntVarP(&concurrency, "concurrency", "c",
+		notify.DefaultStockRegisterOption().Concurrency,
+		"number of symbols fetched at the same time")
 }
 
 var (
 	isAll        bool
+	days         int
+	concurrency  int
 	StockCommand = &cobra.Command{
 		Use:   "update",
 		Short: "Update stock command",
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := cmd.Context()
-			symbols, err := notify.GetSupportSymbols("config.yaml")
+			end := time.Now()
+			db := notify.NewDB(notify.Cfg.DBDSN)
+			option := notify.DefaultStockRegisterOption()
+			option.Concurrency = concurrency
+			register := notify.InitStockRegister(
+				db,
+				notify.NewHTTPClient(),
+				option,
+			)
+			symbols, err := register.TrackedSymbols(ctx)
 			if err != nil {
 				panic(err)
 			}
-			start := time.Now().AddDate(0, 0, -7)
-			if isAll {
-				start = time.Now().AddDate(-5, 0, 0)
+			start := end.AddDate(-5, 0, 0)
+			if !isAll {
+				start, err = register.LookbackStart(ctx, symbols, days, end)
+				if err != nil {
+					panic(err)
+				}
 			}
-			end := time.Now()
-			register := notify.InitStockRegister(
-				notify.NewDB(notify.Cfg.DBDSN),
-				&http.Client{},
-			)
-			if err := register.RegisterStockBySymbols(
+			registerErr := register.RegisterStockBySymbols(
 				ctx,
-				symbols.Symbols,
+				symbols,
 				start,
 				end,
-			); err != nil {
-				panic(err)
+			)
+			if err := evaluateAlerts(cmd, db, symbols); err != nil {
+				log.Println(err)
+			}
+			if registerErr != nil {
+				log.Printf("failed to register %v", notify.FailedSymbols(registerErr))
+				panic(registerErr)
 			}
 		},
 	}
 )
+
+// evaluateAlerts checks price alerts against the freshly registered prices and
+// sends the triggered ones right away.
+func evaluateAlerts(cmd *cobra.Command, db *bun.DB, symbols []string) error {
+	evaluator, err := notify.InitAlertEvaluator(cmd.Context(), db)
+	if err != nil {
+		return err
+	}
+	triggered, err := evaluator.Evaluate(cmd.Context(), symbols, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(triggered) == 0 {
+		return nil
+	}
+	log.Printf("triggered %d alerts", len(triggered))
+	return outbox.Drain(cmd)
+}
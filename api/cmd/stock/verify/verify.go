@@ -0,0 +1,62 @@
+package verify
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func init() {
+	Command.Flags().IntVarP(&days, "days", "d", 365, "number of days to verify")
+	Command.Flags().Float64Var(&sigma, "sigma", notify.DefaultVerifyOption().Sigma,
+		"standard deviations of the daily returns that make a move an outlier")
+	Command.Flags().BoolVarP(&repair, "repair", "r", false,
+		"fetch the prices of the affected ranges again")
+}
+
+var (
+	days    int
+	sigma   float64
+	repair  bool
+	Command = &cobra.Command{
+		Use:   "verify",
+		Short: "Report gaps, outliers and invalid bars in the stored prices",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := cmd.Context()
+			db := notify.NewDB(notify.Cfg.DBDSN)
+			symbols, err := notify.InitTrackedSymbolRepository(db).EnabledSymbols(ctx)
+			if err != nil {
+				panic(err)
+			}
+			verifier := notify.InitStockVerifier(
+				db,
+				notify.NewHTTPClient(),
+				notify.DefaultStockRegisterOption(),
+			)
+			option := notify.DefaultVerifyOption()
+			option.Sigma = sigma
+			// today's close may not be registered yet
+			end := time.Now().AddDate(0, 0, -1)
+			issues, err := verifier.Verify(ctx, symbols, end.AddDate(0, 0, -days), end, option)
+			if err != nil {
+				panic(err)
+			}
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			log.Printf("found %d issues", len(issues))
+			if !repair || len(issues) == 0 {
+				return
+			}
+			if err := verifier.Repair(ctx, issues); err != nil {
+				log.Printf("failed to repair %v", notify.FailedSymbols(err))
+				panic(err)
+			}
+			log.Printf("fetched the prices of %d issues again", len(issues))
+		},
+	}
+)
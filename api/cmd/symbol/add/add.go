@@ -0,0 +1,40 @@
+package add
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func init() {
+	Command.Flags().IntVarP(&priority, "priority", "p", 0,
+		"symbols of a higher priority are fetched first")
+}
+
+var (
+	priority int
+	Command  = &cobra.Command{
+		Use:   "add SYMBOL...",
+		Short: "Track the symbols, or enable them again",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			now := time.Now()
+			symbols := make([]*notify.TrackedSymbol, 0, len(args))
+			for _, arg := range args {
+				symbol, err := notify.NewTrackedSymbol(arg, nil, priority, now)
+				if err != nil {
+					panic(err)
+				}
+				symbols = append(symbols, symbol)
+			}
+			repository := notify.InitTrackedSymbolRepository(notify.NewDB(notify.Cfg.DBDSN))
+			if err := repository.Save(cmd.Context(), symbols); err != nil {
+				panic(err)
+			}
+			log.Printf("tracked %v", args)
+		},
+	}
+)
@@ -0,0 +1,39 @@
+package list
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func init() {
+	Command.Flags().BoolVarP(&all, "all", "a", false, "include the disabled symbols")
+}
+
+var (
+	all     bool
+	Command = &cobra.Command{
+		Use:   "list",
+		Short: "List the tracked symbols in the order they are fetched",
+		Run: func(cmd *cobra.Command, args []string) {
+			repository := notify.InitTrackedSymbolRepository(notify.NewDB(notify.Cfg.DBDSN))
+			symbols, err := repository.GetAll(cmd.Context(), all)
+			if err != nil {
+				panic(err)
+			}
+			for _, symbol := range symbols {
+				status := "enabled"
+				if !symbol.Enabled {
+					status = "disabled"
+				}
+				addedBy := "-"
+				if symbol.AddedBy.Valid {
+					addedBy = symbol.AddedBy.UUID.String()
+				}
+				fmt.Printf("%s\t%s\t%d\t%s\n", symbol.Symbol, status, symbol.Priority, addedBy)
+			}
+		},
+	}
+)
@@ -0,0 +1,26 @@
+package remove
+
+import (
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+var Command = &cobra.Command{
+	Use:   "remove SYMBOL...",
+	Short: "Stop fetching the symbols",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repository := notify.InitTrackedSymbolRepository(notify.NewDB(notify.Cfg.DBDSN))
+		now := time.Now()
+		for _, symbol := range args {
+			if err := repository.Disable(cmd.Context(), symbol, now); err != nil {
+				panic(err)
+			}
+		}
+		log.Printf("disabled %v", args)
+	},
+}
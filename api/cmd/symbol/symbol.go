@@ -0,0 +1,24 @@
+package symbol
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/heyjun3/notify-stock/cmd/symbol/add"
+	"github.com/heyjun3/notify-stock/cmd/symbol/list"
+	"github.com/heyjun3/notify-stock/cmd/symbol/remove"
+)
+
+var (
+	Command = &cobra.Command{
+		Use:   "symbol",
+		Short: "Manage the tracked symbols",
+	}
+)
+
+func init() {
+	Command.AddCommand(
+		add.Command,
+		remove.Command,
+		list.Command,
+	)
+}
@@ -1,24 +1,58 @@
 package graph
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/heyjun3/notify-stock/graph/model"
 	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/shopspring/decimal"
 )
 
 func convertToSymbolDetail(symbol *notify.SymbolDetail) *model.SymbolDetail {
 	if symbol == nil {
 		return nil
 	}
-	return &model.SymbolDetail{
-		ID:             symbol.Symbol,
-		Symbol:         symbol.Symbol,
-		ShortName:      symbol.ShortName,
-		LongName:       symbol.LongName,
-		Price:          symbol.MarketPrice.InexactFloat64(),
-		Change:         symbol.Change(),
-		ChangePercent:  symbol.ChangePercent(),
-		CurrencySymbol: symbol.Currency.Symbol(),
+	nullable := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	detail := &model.SymbolDetail{
+		ID:               symbol.Symbol,
+		Symbol:           symbol.Symbol,
+		ShortName:        symbol.ShortName,
+		LongName:         symbol.LongName,
+		Price:            symbol.MarketPrice.InexactFloat64(),
+		Change:           symbol.Change(),
+		ChangePercent:    symbol.ChangePercent(),
+		ExchangeName:     nullable(symbol.ExchangeName),
+		FullExchangeName: nullable(symbol.FullExchangeName),
+		Timezone:         nullable(symbol.Timezone),
+	}
+	if symbol.Currency != nil && symbol.Currency.IsValid() {
+		currency := model.Currency(symbol.Currency.String())
+		detail.Currency = &currency
+		detail.CurrencySymbol = symbol.Currency.Symbol()
+	}
+	if symbol.InstrumentType != "" {
+		instrumentType := model.InstrumentType(strings.ToUpper(string(symbol.InstrumentType)))
+		detail.InstrumentType = &instrumentType
+	}
+	if symbol.FiftyTwoWeekHigh.Valid {
+		high := symbol.FiftyTwoWeekHigh.Decimal.InexactFloat64()
+		detail.FiftyTwoWeekHigh = &high
+	}
+	if symbol.FiftyTwoWeekLow.Valid {
+		low := symbol.FiftyTwoWeekLow.Decimal.InexactFloat64()
+		detail.FiftyTwoWeekLow = &low
+	}
+	if symbol.FirstTradeDate.Valid {
+		detail.FirstTradeDate = &symbol.FirstTradeDate.Time
 	}
+	return detail
 }
 func convertToSymbolDetails(symbols []*notify.SymbolDetail) []*model.SymbolDetail {
 	if len(symbols) == 0 {
@@ -30,3 +64,195 @@ func convertToSymbolDetails(symbols []*notify.SymbolDetail) []*model.SymbolDetai
 	}
 	return details
 }
+
+func convertToNotificationDelivery(delivery notify.NotificationDelivery) *model.NotificationDelivery {
+	var deliveryError *string
+	if delivery.Error != "" {
+		deliveryError = &delivery.Error
+	}
+	return &model.NotificationDelivery{
+		ID:          delivery.ID.String(),
+		ScheduledAt: delivery.ScheduledAt,
+		Channel:     model.DeliveryChannel(strings.ToUpper(string(delivery.Channel))),
+		Status:      model.DeliveryStatus(strings.ToUpper(string(delivery.Status))),
+		Attempts:    int32(delivery.Attempts),
+		Error:       deliveryError,
+		UpdatedAt:   delivery.UpdatedAt,
+	}
+}
+func convertToNotificationDeliveries(deliveries []notify.NotificationDelivery) []*model.NotificationDelivery {
+	result := make([]*model.NotificationDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, convertToNotificationDelivery(delivery))
+	}
+	return result
+}
+
+func convertToNotificationChannels(channels []*notify.NotificationChannel) []*model.NotificationChannel {
+	result := make([]*model.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		var destination *string
+		if channel.Destination != "" {
+			destination = &channel.Destination
+		}
+		result = append(result, &model.NotificationChannel{
+			Channel:     model.DeliveryChannel(strings.ToUpper(string(channel.Kind))),
+			Destination: destination,
+		})
+	}
+	return result
+}
+
+func convertToAlert(alert *notify.Alert) *model.Alert {
+	var lastTriggeredAt *time.Time
+	if !alert.LastTriggeredAt.IsZero() {
+		lastTriggeredAt = &alert.LastTriggeredAt
+	}
+	result := &model.Alert{
+		ID:              alert.ID.String(),
+		Symbol:          alert.Symbol,
+		Condition:       model.AlertCondition(strings.ToUpper(string(alert.Condition))),
+		CooldownMinutes: int32(alert.CooldownMinutes),
+		Paused:          alert.Paused,
+		LastTriggeredAt: lastTriggeredAt,
+	}
+	if !alert.Threshold.IsZero() {
+		threshold := alert.Threshold.InexactFloat64()
+		result.Threshold = &threshold
+	}
+	if !alert.UpperThreshold.IsZero() {
+		upper := alert.UpperThreshold.InexactFloat64()
+		result.UpperThreshold = &upper
+	}
+	if alert.ShortWindow != 0 {
+		short := int32(alert.ShortWindow)
+		result.ShortWindow = &short
+	}
+	if alert.LongWindow != 0 {
+		long := int32(alert.LongWindow)
+		result.LongWindow = &long
+	}
+	return result
+}
+func convertToAlerts(alerts []*notify.Alert) []*model.Alert {
+	result := make([]*model.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		result = append(result, convertToAlert(alert))
+	}
+	return result
+}
+
+func convertToIndicatorPoints(points []notify.IndicatorPoint) []*model.IndicatorPoint {
+	nullable := func(d decimal.NullDecimal) *float64 {
+		if !d.Valid {
+			return nil
+		}
+		f := d.Decimal.InexactFloat64()
+		return &f
+	}
+	result := make([]*model.IndicatorPoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, &model.IndicatorPoint{
+			Timestamp: point.Timestamp,
+			Value:     point.Value.InexactFloat64(),
+			Upper:     nullable(point.Upper),
+			Lower:     nullable(point.Lower),
+			Signal:    nullable(point.Signal),
+			Histogram: nullable(point.Histogram),
+		})
+	}
+	return result
+}
+
+func convertToStocks(stocks []notify.Stock) []*model.Stock {
+	result := make([]*model.Stock, 0, len(stocks))
+	for _, stock := range stocks {
+		s := &model.Stock{
+			Symbol:    stock.Symbol,
+			Price:     stock.Close,
+			Timestamp: stock.Timestamp.Format("2006/01/02"),
+		}
+		if stock.Volume.Valid {
+			volume := strconv.FormatInt(stock.Volume.Int64, 10)
+			s.Volume = &volume
+		}
+		if stock.AdjClose.Valid {
+			s.AdjClose = &stock.AdjClose.Float64
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func convertToIntradayStocks(stocks []notify.IntradayStock) []*model.Stock {
+	result := make([]*model.Stock, 0, len(stocks))
+	for _, stock := range stocks {
+		result = append(result, &model.Stock{
+			Symbol:    stock.Symbol,
+			Price:     stock.Close,
+			Timestamp: stock.Timestamp.Format(time.RFC3339),
+		})
+	}
+	return result
+}
+
+func convertToDividends(dividends []notify.Dividend) []*model.Dividend {
+	result := make([]*model.Dividend, 0, len(dividends))
+	for _, dividend := range dividends {
+		result = append(result, &model.Dividend{
+			ExDate: dividend.ExDate,
+			Amount: dividend.Amount.InexactFloat64(),
+		})
+	}
+	return result
+}
+
+func convertToSplits(splits []notify.Split) []*model.Split {
+	result := make([]*model.Split, 0, len(splits))
+	for _, split := range splits {
+		result = append(result, &model.Split{
+			Date:        split.Date,
+			Numerator:   int32(split.Numerator),
+			Denominator: int32(split.Denominator),
+			Ratio:       split.String(),
+		})
+	}
+	return result
+}
+
+func convertToTrackedSymbol(symbol *notify.TrackedSymbol) *model.TrackedSymbol {
+	var addedBy *string
+	if symbol.AddedBy.Valid {
+		id := symbol.AddedBy.UUID.String()
+		addedBy = &id
+	}
+	return &model.TrackedSymbol{
+		Symbol:    symbol.Symbol,
+		Enabled:   symbol.Enabled,
+		Priority:  int32(symbol.Priority),
+		AddedBy:   addedBy,
+		CreatedAt: symbol.CreatedAt,
+		UpdatedAt: symbol.UpdatedAt,
+	}
+}
+func convertToTrackedSymbols(symbols []*notify.TrackedSymbol) []*model.TrackedSymbol {
+	result := make([]*model.TrackedSymbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		result = append(result, convertToTrackedSymbol(symbol))
+	}
+	return result
+}
+
+func convertToSymbolCandidates(candidates []notify.SymbolCandidate) []*model.SymbolCandidate {
+	result := make([]*model.SymbolCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		result = append(result, &model.SymbolCandidate{
+			Symbol:         candidate.Symbol,
+			ShortName:      candidate.ShortName,
+			LongName:       candidate.LongName,
+			ExchangeName:   candidate.ExchangeName,
+			InstrumentType: model.InstrumentType(strings.ToUpper(string(candidate.InstrumentType))),
+		})
+	}
+	return result
+}
@@ -49,8 +49,39 @@ func NewAuthDirective(logger *slog.Logger) Directive {
 	}
 }
 
-func NewDirectiveRoot(auth Directive) *DirectiveRoot {
+// AdminDirective authenticates the member like the auth directive, and then
+// rejects the members who are not administrators.
+type AdminDirective Directive
+
+func NewAdminDirective(logger *slog.Logger, memberRepository *notifystock.MemberRepository) AdminDirective {
+	auth := NewAuthDirective(logger)
+	return func(ctx context.Context, obj any, next graphql.Resolver) (any, error) {
+		return auth(ctx, obj, func(ctx context.Context) (any, error) {
+			memberID, err := GetMemberID(ctx)
+			if err != nil {
+				return nil, err
+			}
+			member, err := memberRepository.GetByID(ctx, *memberID)
+			if err != nil {
+				return nil, err
+			}
+			if !member.IsAdmin {
+				logger.Warn("member is not an administrator", "memberID", member.ID)
+				return nil, &gqlerror.Error{
+					Message: "administrator only",
+					Extensions: map[string]any{
+						"code": graphError.Forbidden,
+					},
+				}
+			}
+			return next(ctx)
+		})
+	}
+}
+
+func NewDirectiveRoot(auth Directive, admin AdminDirective) *DirectiveRoot {
 	return &DirectiveRoot{
-		Auth: auth,
+		Auth:  auth,
+		Admin: admin,
 	}
 }
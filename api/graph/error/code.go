@@ -2,5 +2,6 @@ package grapherror
 
 const (
 	UnAuthorized        = "UNAUTHORIZED"
+	Forbidden           = "FORBIDDEN"
 	InternalServerError = "INTERNAL_SERVER_ERROR"
 )
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+// Currency is an ISO 4217 currency code, validated against the currencies
+// notify supports.
+type Currency string
+
+func (c Currency) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(string(c)))
+}
+
+func (c *Currency) UnmarshalGQL(v any) error {
+	code, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("currency must be a string")
+	}
+	currency, err := notify.ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+	*c = Currency(currency)
+	return nil
+}
@@ -3,6 +3,10 @@
 package model
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
@@ -11,62 +15,708 @@ type Node interface {
 	GetID() string
 }
 
+type Alert struct {
+	ID        string         `json:"id"`
+	Symbol    string         `json:"symbol"`
+	Condition AlertCondition `json:"condition"`
+	// Price for ABOVE, BELOW and CROSSES. Percent for CHANGE_PERCENT.
+	// Lower bound of the closing price to moving average ratio in percent for RATIO_BAND.
+	Threshold *float64 `json:"threshold,omitempty"`
+	// Upper bound of the ratio in percent for RATIO_BAND.
+	UpperThreshold *float64 `json:"upperThreshold,omitempty"`
+	// Moving average windows in trading days for GOLDEN_CROSS, DEATH_CROSS and RATIO_BAND.
+	ShortWindow     *int32     `json:"shortWindow,omitempty"`
+	LongWindow      *int32     `json:"longWindow,omitempty"`
+	CooldownMinutes int32      `json:"cooldownMinutes"`
+	Paused          bool       `json:"paused"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt,omitempty"`
+}
+
+func (Alert) IsNode()            {}
+func (this Alert) GetID() string { return this.ID }
+
+type AlertInput struct {
+	Symbol         string         `json:"symbol"`
+	Condition      AlertCondition `json:"condition"`
+	Threshold      *float64       `json:"threshold,omitempty"`
+	UpperThreshold *float64       `json:"upperThreshold,omitempty"`
+	// Defaults to 50 and 200 trading days.
+	ShortWindow     *int32 `json:"shortWindow,omitempty"`
+	LongWindow      *int32 `json:"longWindow,omitempty"`
+	CooldownMinutes *int32 `json:"cooldownMinutes,omitempty"`
+}
+
 type ChartInput struct {
 	Symbol *string   `json:"symbol,omitempty"`
 	Start  time.Time `json:"start"`
 	End    time.Time `json:"end"`
+	// Width of each bar. Intraday intervals are kept for 7 days (ONE_MINUTE),
+	// 60 days (FIVE_MINUTES) and 2 years (ONE_HOUR). Defaults to ONE_DAY.
+	Interval *Interval `json:"interval,omitempty"`
+}
+
+type Dividend struct {
+	ExDate time.Time `json:"exDate"`
+	Amount float64   `json:"amount"`
+}
+
+type IndicatorInput struct {
+	Kind  IndicatorKind `json:"kind"`
+	Start time.Time     `json:"start"`
+	End   time.Time     `json:"end"`
+	// Window in trading days. Defaults to 14 for RSI and ATR and 20 otherwise.
+	Period *int32 `json:"period,omitempty"`
+	// MACD windows. Default to 12, 26 and 9.
+	FastPeriod   *int32 `json:"fastPeriod,omitempty"`
+	SlowPeriod   *int32 `json:"slowPeriod,omitempty"`
+	SignalPeriod *int32 `json:"signalPeriod,omitempty"`
+	// Width of the Bollinger bands in standard deviations. Defaults to 2.
+	StdDev *float64 `json:"stdDev,omitempty"`
+}
+
+// A value of an indicator on a trading day.
+// BOLLINGER sets upper and lower around the middle band in value.
+// MACD sets signal and histogram around the MACD line in value.
+type IndicatorPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+	Upper     *float64  `json:"upper,omitempty"`
+	Lower     *float64  `json:"lower,omitempty"`
+	Signal    *float64  `json:"signal,omitempty"`
+	Histogram *float64  `json:"histogram,omitempty"`
 }
 
 type Mutation struct {
 }
 
 type Notification struct {
-	ID      string          `json:"id"`
-	Time    time.Time       `json:"time"`
-	Hour    time.Time       `json:"hour"`
-	Targets []*SymbolDetail `json:"targets"`
+	ID                  string                  `json:"id"`
+	Time                time.Time               `json:"time"`
+	Hour                time.Time               `json:"hour"`
+	Targets             []*SymbolDetail         `json:"targets"`
+	MovingAverageWindow MovingAverageWindow     `json:"movingAverageWindow"`
+	Channels            []*NotificationChannel  `json:"channels"`
+	Deliveries          []*NotificationDelivery `json:"deliveries"`
 }
 
 func (Notification) IsNode()            {}
 func (this Notification) GetID() string { return this.ID }
 
+type NotificationChannel struct {
+	Channel     DeliveryChannel `json:"channel"`
+	Destination *string         `json:"destination,omitempty"`
+}
+
+type NotificationChannelInput struct {
+	Channel DeliveryChannel `json:"channel"`
+	// Webhook URL for SLACK, DISCORD and WEBHOOK. EMAIL defaults to the member's address.
+	Destination *string `json:"destination,omitempty"`
+}
+
+type NotificationDelivery struct {
+	ID          string          `json:"id"`
+	ScheduledAt time.Time       `json:"scheduledAt"`
+	Channel     DeliveryChannel `json:"channel"`
+	Status      DeliveryStatus  `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	Error       *string         `json:"error,omitempty"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func (NotificationDelivery) IsNode()            {}
+func (this NotificationDelivery) GetID() string { return this.ID }
+
 type NotificationInput struct {
-	Symbols []string  `json:"symbols"`
-	Time    time.Time `json:"time"`
+	Symbols  []string                    `json:"symbols"`
+	Time     time.Time                   `json:"time"`
+	Channels []*NotificationChannelInput `json:"channels,omitempty"`
+	// Defaults to MONTHS_12.
+	MovingAverageWindow *MovingAverageWindow `json:"movingAverageWindow,omitempty"`
 }
 
 type Query struct {
 }
 
+// A stock split turning every denominator shares into numerator shares.
+type Split struct {
+	Date        time.Time `json:"date"`
+	Numerator   int32     `json:"numerator"`
+	Denominator int32     `json:"denominator"`
+	// Ratio such as "4:1".
+	Ratio string `json:"ratio"`
+}
+
 type Stock struct {
 	Symbol    string  `json:"symbol"`
 	Timestamp string  `json:"timestamp"`
 	Price     float64 `json:"price"`
+	Volume    *string `json:"volume,omitempty"`
+	// Close adjusted for splits and dividends. Null on intraday bars.
+	AdjClose *float64 `json:"adjClose,omitempty"`
 }
 
 type Symbol struct {
-	ID     string        `json:"id"`
-	Symbol string        `json:"symbol"`
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	// Prices and change are converted into currency by the latest FX rate when it
+	// is given, and currency and currencySymbol are those of currency.
 	Detail *SymbolDetail `json:"detail"`
-	Chart  []*Stock      `json:"chart"`
+	// Prices are converted into currency by the FX rate of each day when it is
+	// given. Days without a registered rate are left out.
+	Chart      []*Stock          `json:"chart"`
+	Indicators []*IndicatorPoint `json:"indicators"`
+	Dividends  []*Dividend       `json:"dividends"`
+	Splits     []*Split          `json:"splits"`
 }
 
 func (Symbol) IsNode()            {}
 func (this Symbol) GetID() string { return this.ID }
 
+// A symbol found by searchSymbols, which may not be tracked yet.
+type SymbolCandidate struct {
+	Symbol         string         `json:"symbol"`
+	ShortName      string         `json:"shortName"`
+	LongName       string         `json:"longName"`
+	ExchangeName   string         `json:"exchangeName"`
+	InstrumentType InstrumentType `json:"instrumentType"`
+}
+
 type SymbolDetail struct {
-	ID             string  `json:"id"`
-	Symbol         string  `json:"symbol"`
-	ShortName      string  `json:"shortName"`
-	LongName       string  `json:"longName"`
-	Price          float64 `json:"price"`
-	Change         string  `json:"change"`
-	ChangePercent  string  `json:"changePercent"`
-	Volume         *string `json:"volume,omitempty"`
-	MarketCap      *string `json:"marketCap,omitempty"`
-	CurrencySymbol string  `json:"currencySymbol"`
+	ID             string    `json:"id"`
+	Symbol         string    `json:"symbol"`
+	ShortName      string    `json:"shortName"`
+	LongName       string    `json:"longName"`
+	Price          float64   `json:"price"`
+	Change         string    `json:"change"`
+	ChangePercent  string    `json:"changePercent"`
+	Volume         *string   `json:"volume,omitempty"`
+	MarketCap      *string   `json:"marketCap,omitempty"`
+	CurrencySymbol string    `json:"currencySymbol"`
+	Currency       *Currency `json:"currency,omitempty"`
+	// Exchange code of Yahoo Finance, e.g. "NMS".
+	ExchangeName     *string         `json:"exchangeName,omitempty"`
+	FullExchangeName *string         `json:"fullExchangeName,omitempty"`
+	InstrumentType   *InstrumentType `json:"instrumentType,omitempty"`
+	// IANA time zone of the exchange, e.g. "Asia/Tokyo".
+	Timezone         *string    `json:"timezone,omitempty"`
+	FiftyTwoWeekHigh *float64   `json:"fiftyTwoWeekHigh,omitempty"`
+	FiftyTwoWeekLow  *float64   `json:"fiftyTwoWeekLow,omitempty"`
+	FirstTradeDate   *time.Time `json:"firstTradeDate,omitempty"`
 }
 
 type SymbolInput struct {
 	Symbol string `json:"symbol"`
 }
+
+// A symbol whose prices are fetched and which members can be notified of.
+type TrackedSymbol struct {
+	Symbol  string `json:"symbol"`
+	Enabled bool   `json:"enabled"`
+	// Symbols of a higher priority are fetched first.
+	Priority int32 `json:"priority"`
+	// Member who added the symbol. Null when it was added from the CLI.
+	AddedBy   *string   `json:"addedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type TrackedSymbolInput struct {
+	Symbol string `json:"symbol"`
+	// Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Defaults to 0.
+	Priority *int32 `json:"priority,omitempty"`
+}
+
+type AlertCondition string
+
+const (
+	AlertConditionAbove         AlertCondition = "ABOVE"
+	AlertConditionBelow         AlertCondition = "BELOW"
+	AlertConditionCrosses       AlertCondition = "CROSSES"
+	AlertConditionChangePercent AlertCondition = "CHANGE_PERCENT"
+	AlertConditionGoldenCross   AlertCondition = "GOLDEN_CROSS"
+	AlertConditionDeathCross    AlertCondition = "DEATH_CROSS"
+	AlertConditionRatioBand     AlertCondition = "RATIO_BAND"
+)
+
+var AllAlertCondition = []AlertCondition{
+	AlertConditionAbove,
+	AlertConditionBelow,
+	AlertConditionCrosses,
+	AlertConditionChangePercent,
+	AlertConditionGoldenCross,
+	AlertConditionDeathCross,
+	AlertConditionRatioBand,
+}
+
+func (e AlertCondition) IsValid() bool {
+	switch e {
+	case AlertConditionAbove, AlertConditionBelow, AlertConditionCrosses, AlertConditionChangePercent, AlertConditionGoldenCross, AlertConditionDeathCross, AlertConditionRatioBand:
+		return true
+	}
+	return false
+}
+
+func (e AlertCondition) String() string {
+	return string(e)
+}
+
+func (e *AlertCondition) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertCondition(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertCondition", str)
+	}
+	return nil
+}
+
+func (e AlertCondition) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *AlertCondition) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e AlertCondition) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DeliveryChannel string
+
+const (
+	DeliveryChannelEmail   DeliveryChannel = "EMAIL"
+	DeliveryChannelSLACk   DeliveryChannel = "SLACK"
+	DeliveryChannelDiscord DeliveryChannel = "DISCORD"
+	DeliveryChannelWebhook DeliveryChannel = "WEBHOOK"
+)
+
+var AllDeliveryChannel = []DeliveryChannel{
+	DeliveryChannelEmail,
+	DeliveryChannelSLACk,
+	DeliveryChannelDiscord,
+	DeliveryChannelWebhook,
+}
+
+func (e DeliveryChannel) IsValid() bool {
+	switch e {
+	case DeliveryChannelEmail, DeliveryChannelSLACk, DeliveryChannelDiscord, DeliveryChannelWebhook:
+		return true
+	}
+	return false
+}
+
+func (e DeliveryChannel) String() string {
+	return string(e)
+}
+
+func (e *DeliveryChannel) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeliveryChannel(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeliveryChannel", str)
+	}
+	return nil
+}
+
+func (e DeliveryChannel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DeliveryChannel) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DeliveryChannel) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "PENDING"
+	DeliveryStatusSent    DeliveryStatus = "SENT"
+	DeliveryStatusFailed  DeliveryStatus = "FAILED"
+	DeliveryStatusDead    DeliveryStatus = "DEAD"
+)
+
+var AllDeliveryStatus = []DeliveryStatus{
+	DeliveryStatusPending,
+	DeliveryStatusSent,
+	DeliveryStatusFailed,
+	DeliveryStatusDead,
+}
+
+func (e DeliveryStatus) IsValid() bool {
+	switch e {
+	case DeliveryStatusPending, DeliveryStatusSent, DeliveryStatusFailed, DeliveryStatusDead:
+		return true
+	}
+	return false
+}
+
+func (e DeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *DeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e DeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type IndicatorKind string
+
+const (
+	IndicatorKindSma        IndicatorKind = "SMA"
+	IndicatorKindEma        IndicatorKind = "EMA"
+	IndicatorKindRsi        IndicatorKind = "RSI"
+	IndicatorKindMacd       IndicatorKind = "MACD"
+	IndicatorKindBollinger  IndicatorKind = "BOLLINGER"
+	IndicatorKindAtr        IndicatorKind = "ATR"
+	IndicatorKindVolatility IndicatorKind = "VOLATILITY"
+)
+
+var AllIndicatorKind = []IndicatorKind{
+	IndicatorKindSma,
+	IndicatorKindEma,
+	IndicatorKindRsi,
+	IndicatorKindMacd,
+	IndicatorKindBollinger,
+	IndicatorKindAtr,
+	IndicatorKindVolatility,
+}
+
+func (e IndicatorKind) IsValid() bool {
+	switch e {
+	case IndicatorKindSma, IndicatorKindEma, IndicatorKindRsi, IndicatorKindMacd, IndicatorKindBollinger, IndicatorKindAtr, IndicatorKindVolatility:
+		return true
+	}
+	return false
+}
+
+func (e IndicatorKind) String() string {
+	return string(e)
+}
+
+func (e *IndicatorKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = IndicatorKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid IndicatorKind", str)
+	}
+	return nil
+}
+
+func (e IndicatorKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *IndicatorKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e IndicatorKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type InstrumentType string
+
+const (
+	InstrumentTypeEquity         InstrumentType = "EQUITY"
+	InstrumentTypeEtf            InstrumentType = "ETF"
+	InstrumentTypeIndex          InstrumentType = "INDEX"
+	InstrumentTypeMutualfund     InstrumentType = "MUTUALFUND"
+	InstrumentTypeCurrency       InstrumentType = "CURRENCY"
+	InstrumentTypeCryptocurrency InstrumentType = "CRYPTOCURRENCY"
+	InstrumentTypeFuture         InstrumentType = "FUTURE"
+)
+
+var AllInstrumentType = []InstrumentType{
+	InstrumentTypeEquity,
+	InstrumentTypeEtf,
+	InstrumentTypeIndex,
+	InstrumentTypeMutualfund,
+	InstrumentTypeCurrency,
+	InstrumentTypeCryptocurrency,
+	InstrumentTypeFuture,
+}
+
+func (e InstrumentType) IsValid() bool {
+	switch e {
+	case InstrumentTypeEquity, InstrumentTypeEtf, InstrumentTypeIndex, InstrumentTypeMutualfund, InstrumentTypeCurrency, InstrumentTypeCryptocurrency, InstrumentTypeFuture:
+		return true
+	}
+	return false
+}
+
+func (e InstrumentType) String() string {
+	return string(e)
+}
+
+func (e *InstrumentType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InstrumentType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InstrumentType", str)
+	}
+	return nil
+}
+
+func (e InstrumentType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *InstrumentType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e InstrumentType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Interval string
+
+const (
+	IntervalOneMinute   Interval = "ONE_MINUTE"
+	IntervalFiveMinutes Interval = "FIVE_MINUTES"
+	IntervalOneHour     Interval = "ONE_HOUR"
+	IntervalOneDay      Interval = "ONE_DAY"
+)
+
+var AllInterval = []Interval{
+	IntervalOneMinute,
+	IntervalFiveMinutes,
+	IntervalOneHour,
+	IntervalOneDay,
+}
+
+func (e Interval) IsValid() bool {
+	switch e {
+	case IntervalOneMinute, IntervalFiveMinutes, IntervalOneHour, IntervalOneDay:
+		return true
+	}
+	return false
+}
+
+func (e Interval) String() string {
+	return string(e)
+}
+
+func (e *Interval) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Interval(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Interval", str)
+	}
+	return nil
+}
+
+func (e Interval) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Interval) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Interval) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Language and number format of the summary mails.
+type Locale string
+
+const (
+	LocaleJa Locale = "JA"
+	LocaleEn Locale = "EN"
+)
+
+var AllLocale = []Locale{
+	LocaleJa,
+	LocaleEn,
+}
+
+func (e Locale) IsValid() bool {
+	switch e {
+	case LocaleJa, LocaleEn:
+		return true
+	}
+	return false
+}
+
+func (e Locale) String() string {
+	return string(e)
+}
+
+func (e *Locale) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Locale(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Locale", str)
+	}
+	return nil
+}
+
+func (e Locale) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Locale) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Locale) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// Moving average the closing price is compared with in the summary.
+// DAYS_* count trading days and MONTHS_* count calendar months.
+type MovingAverageWindow string
+
+const (
+	MovingAverageWindowDays25   MovingAverageWindow = "DAYS_25"
+	MovingAverageWindowDays75   MovingAverageWindow = "DAYS_75"
+	MovingAverageWindowDays200  MovingAverageWindow = "DAYS_200"
+	MovingAverageWindowMonths3  MovingAverageWindow = "MONTHS_3"
+	MovingAverageWindowMonths6  MovingAverageWindow = "MONTHS_6"
+	MovingAverageWindowMonths12 MovingAverageWindow = "MONTHS_12"
+)
+
+var AllMovingAverageWindow = []MovingAverageWindow{
+	MovingAverageWindowDays25,
+	MovingAverageWindowDays75,
+	MovingAverageWindowDays200,
+	MovingAverageWindowMonths3,
+	MovingAverageWindowMonths6,
+	MovingAverageWindowMonths12,
+}
+
+func (e MovingAverageWindow) IsValid() bool {
+	switch e {
+	case MovingAverageWindowDays25, MovingAverageWindowDays75, MovingAverageWindowDays200, MovingAverageWindowMonths3, MovingAverageWindowMonths6, MovingAverageWindowMonths12:
+		return true
+	}
+	return false
+}
+
+func (e MovingAverageWindow) String() string {
+	return string(e)
+}
+
+func (e *MovingAverageWindow) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MovingAverageWindow(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MovingAverageWindow", str)
+	}
+	return nil
+}
+
+func (e MovingAverageWindow) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MovingAverageWindow) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MovingAverageWindow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
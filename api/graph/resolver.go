@@ -11,27 +11,57 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	stockRepository        *notify.StockRepository
-	symbolRepository       *notify.SymbolRepository
-	notificationRepository *notify.NotificationRepository
-	notificationCreator    *notify.NotificationCreator
-	logger                 *slog.Logger
-	loader                 *notify.DataLoader
+	stockRepository           *notify.StockRepository
+	intradayRepository        *notify.IntradayRepository
+	symbolRepository          *notify.SymbolRepository
+	corporateActionRepository *notify.CorporateActionRepository
+	trackedSymbolRepository   *notify.TrackedSymbolRepository
+	symbolTracker             *notify.SymbolTracker
+	notificationRepository    *notify.NotificationRepository
+	notificationCreator       *notify.NotificationCreator
+	memberRepository          *notify.MemberRepository
+	deliveryRepository        *notify.NotificationDeliveryRepository
+	alertRepository           *notify.AlertRepository
+	alertCreator              *notify.AlertCreator
+	indicatorService          *notify.IndicatorService
+	currencyConverter         *notify.CurrencyConverter
+	logger                    *slog.Logger
+	loader                    *notify.DataLoader
 }
 
 func NewResolver(
 	stockRepository *notify.StockRepository,
+	intradayRepository *notify.IntradayRepository,
 	symbolRepository *notify.SymbolRepository,
+	corporateActionRepository *notify.CorporateActionRepository,
+	trackedSymbolRepository *notify.TrackedSymbolRepository,
+	symbolTracker *notify.SymbolTracker,
 	notificationRepository *notify.NotificationRepository,
 	notificationCreator *notify.NotificationCreator,
+	memberRepository *notify.MemberRepository,
+	deliveryRepository *notify.NotificationDeliveryRepository,
+	alertRepository *notify.AlertRepository,
+	alertCreator *notify.AlertCreator,
+	indicatorService *notify.IndicatorService,
+	currencyConverter *notify.CurrencyConverter,
 	loader *notify.DataLoader,
 ) *Resolver {
 	return &Resolver{
-		stockRepository:        stockRepository,
-		symbolRepository:       symbolRepository,
-		notificationRepository: notificationRepository,
-		notificationCreator:    notificationCreator,
-		logger:                 notify.CreateLogger("info"),
-		loader:                 loader,
+		stockRepository:           stockRepository,
+		intradayRepository:        intradayRepository,
+		symbolRepository:          symbolRepository,
+		corporateActionRepository: corporateActionRepository,
+		trackedSymbolRepository:   trackedSymbolRepository,
+		symbolTracker:             symbolTracker,
+		notificationRepository:    notificationRepository,
+		notificationCreator:       notificationCreator,
+		memberRepository:          memberRepository,
+		deliveryRepository:        deliveryRepository,
+		alertRepository:           alertRepository,
+		alertCreator:              alertCreator,
+		indicatorService:          indicatorService,
+		currencyConverter:         currencyConverter,
+		logger:                    notify.CreateLogger("info"),
+		loader:                    loader,
 	}
 }
@@ -6,11 +6,16 @@ package graph
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/heyjun3/notify-stock/graph/model"
+	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/shopspring/decimal"
 )
 
 // CreateNotification is the resolver for the createNotification field.
@@ -19,7 +24,21 @@ func (r *mutationResolver) CreateNotification(ctx context.Context, input model.N
 	if err != nil {
 		return nil, err
 	}
-	notification, err := r.notificationCreator.Create(ctx, *memberID, input.Symbols, input.Time)
+	channels := make([]notify.NotificationChannelSetting, 0, len(input.Channels))
+	for _, channel := range input.Channels {
+		setting := notify.NotificationChannelSetting{
+			Kind: notify.DeliveryChannel(strings.ToLower(string(channel.Channel))),
+		}
+		if channel.Destination != nil {
+			setting.Destination = *channel.Destination
+		}
+		channels = append(channels, setting)
+	}
+	var window notify.MovingAverageWindow
+	if input.MovingAverageWindow != nil {
+		window = notify.MovingAverageWindow(strings.ToLower(string(*input.MovingAverageWindow)))
+	}
+	notification, err := r.notificationCreator.Create(ctx, *memberID, input.Symbols, input.Time, channels, window)
 	if err != nil {
 		return nil, err
 	}
@@ -30,9 +49,11 @@ func (r *mutationResolver) CreateNotification(ctx context.Context, input model.N
 		})
 	}
 	return &model.Notification{
-		ID:      notification.ID.String(),
-		Time:    notification.Time.Hour,
-		Targets: targets,
+		ID:                  notification.ID.String(),
+		Time:                notification.Time.Hour,
+		Targets:             targets,
+		MovingAverageWindow: model.MovingAverageWindow(strings.ToUpper(string(notification.Window))),
+		Channels:            convertToNotificationChannels(notification.Channels),
 	}, nil
 }
 
@@ -49,6 +70,136 @@ func (r *mutationResolver) DeleteNotification(ctx context.Context) (string, erro
 	return deletedNotification[0].ID.String(), nil
 }
 
+// CreateAlert is the resolver for the createAlert field.
+func (r *mutationResolver) CreateAlert(ctx context.Context, input model.AlertInput) (*model.Alert, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cooldown := notify.DefaultAlertCooldown
+	if input.CooldownMinutes != nil {
+		cooldown = time.Duration(*input.CooldownMinutes) * time.Minute
+	}
+	var threshold decimal.Decimal
+	if input.Threshold != nil {
+		threshold = decimal.NewFromFloat(*input.Threshold)
+	}
+	opts := make([]notify.AlertOption, 0, 2)
+	if input.UpperThreshold != nil {
+		opts = append(opts, notify.WithUpperThreshold(decimal.NewFromFloat(*input.UpperThreshold)))
+	}
+	if input.ShortWindow != nil || input.LongWindow != nil {
+		var short, long int
+		if input.ShortWindow != nil {
+			short = int(*input.ShortWindow)
+		}
+		if input.LongWindow != nil {
+			long = int(*input.LongWindow)
+		}
+		opts = append(opts, notify.WithMovingAverageWindows(short, long))
+	}
+	alert, err := r.alertCreator.Create(
+		ctx, *memberID, input.Symbol,
+		notify.AlertCondition(strings.ToLower(string(input.Condition))),
+		threshold, cooldown, opts...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return convertToAlert(alert), nil
+}
+
+// PauseAlert is the resolver for the pauseAlert field.
+func (r *mutationResolver) PauseAlert(ctx context.Context, id string, paused bool) (*model.Alert, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alertID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, notify.NewValidationError("Invalid alert ID", err.Error())
+	}
+	alert, err := r.alertRepository.SetPaused(ctx, *memberID, alertID, paused)
+	if err != nil {
+		return nil, err
+	}
+	return convertToAlert(alert), nil
+}
+
+// DeleteAlert is the resolver for the deleteAlert field.
+func (r *mutationResolver) DeleteAlert(ctx context.Context, id string) (string, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return "", err
+	}
+	alertID, err := uuid.Parse(id)
+	if err != nil {
+		return "", notify.NewValidationError("Invalid alert ID", err.Error())
+	}
+	if err := r.alertRepository.Delete(ctx, *memberID, alertID); err != nil {
+		return "", err
+	}
+	return alertID.String(), nil
+}
+
+// UpdateLocale is the resolver for the updateLocale field.
+func (r *mutationResolver) UpdateLocale(ctx context.Context, locale model.Locale) (model.Locale, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := r.memberRepository.UpdateLocale(
+		ctx, *memberID, notify.Locale(strings.ToLower(string(locale))),
+	); err != nil {
+		return "", err
+	}
+	return locale, nil
+}
+
+// UpdateTrackedSymbol is the resolver for the updateTrackedSymbol field.
+func (r *mutationResolver) UpdateTrackedSymbol(ctx context.Context, input model.TrackedSymbolInput) (*model.TrackedSymbol, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tracked, err := r.trackedSymbolRepository.Get(ctx, input.Symbol)
+	if errors.Is(err, sql.ErrNoRows) {
+		tracked, err = notify.NewTrackedSymbol(input.Symbol, memberID, 0, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if input.Enabled != nil {
+		tracked.Enabled = *input.Enabled
+	}
+	if input.Priority != nil {
+		tracked.Priority = int(*input.Priority)
+	}
+	tracked.UpdatedAt = now
+	if err := r.trackedSymbolRepository.Save(ctx, []*notify.TrackedSymbol{tracked}); err != nil {
+		return nil, err
+	}
+	return convertToTrackedSymbol(tracked), nil
+}
+
+// TrackSymbol is the resolver for the trackSymbol field.
+func (r *mutationResolver) TrackSymbol(ctx context.Context, symbol string) (*model.Symbol, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := r.symbolTracker.Track(ctx, *memberID, symbol, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &model.Symbol{
+		ID:     detail.Symbol,
+		Symbol: detail.Symbol,
+		Detail: convertToSymbolDetail(detail),
+	}, nil
+}
+
 // Hour is the resolver for the hour field.
 func (r *notificationResolver) Hour(ctx context.Context, obj *model.Notification) (*time.Time, error) {
 	hour := obj.Time.AddDate(2022, 0, 0)
@@ -68,6 +219,19 @@ func (r *notificationResolver) Targets(ctx context.Context, obj *model.Notificat
 	return convertToSymbolDetails(details), nil
 }
 
+// Deliveries is the resolver for the deliveries field.
+func (r *notificationResolver) Deliveries(ctx context.Context, obj *model.Notification) ([]*model.NotificationDelivery, error) {
+	id, err := uuid.Parse(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := r.deliveryRepository.GetByNotificationID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertToNotificationDeliveries(deliveries), nil
+}
+
 // Node is the resolver for the node field.
 func (r *queryResolver) Node(ctx context.Context, id string) (model.Node, error) {
 	panic(fmt.Errorf("not implemented: Node - node"))
@@ -82,9 +246,16 @@ func (r *queryResolver) Symbol(ctx context.Context, input model.SymbolInput) (*m
 }
 
 // Symbols is the resolver for the symbols field.
-func (r *queryResolver) Symbols(ctx context.Context, input *model.SymbolInput) ([]*model.Symbol, error) {
+func (r *queryResolver) Symbols(ctx context.Context, input *model.SymbolInput, instrumentType *model.InstrumentType) ([]*model.Symbol, error) {
 	if input == nil {
-		symbols, err := r.symbolRepository.GetAll(ctx)
+		var symbols []notify.SymbolDetail
+		var err error
+		if instrumentType != nil {
+			symbols, err = r.symbolRepository.GetByInstrumentType(
+				ctx, notify.InstrumentType(strings.ToLower(string(*instrumentType))))
+		} else {
+			symbols, err = r.symbolRepository.GetAll(ctx)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -94,15 +265,7 @@ func (r *queryResolver) Symbols(ctx context.Context, input *model.SymbolInput) (
 			sym = append(sym, &model.Symbol{
 				ID:     symbol.Symbol,
 				Symbol: symbol.Symbol,
-				Detail: &model.SymbolDetail{
-					Symbol:         symbol.Symbol,
-					ShortName:      symbol.ShortName,
-					LongName:       symbol.LongName,
-					Price:          symbol.MarketPrice.InexactFloat64(),
-					Change:         symbol.Change(),
-					ChangePercent:  symbol.ChangePercent(),
-					CurrencySymbol: symbol.Currency.Symbol(),
-				},
+				Detail: convertToSymbolDetail(&symbol),
 			})
 		}
 		return sym, nil
@@ -116,6 +279,24 @@ func (r *queryResolver) Symbols(ctx context.Context, input *model.SymbolInput) (
 	}, nil
 }
 
+// TrackedSymbols is the resolver for the trackedSymbols field.
+func (r *queryResolver) TrackedSymbols(ctx context.Context) ([]*model.TrackedSymbol, error) {
+	tracked, err := r.trackedSymbolRepository.GetAll(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return convertToTrackedSymbols(tracked), nil
+}
+
+// SearchSymbols is the resolver for the searchSymbols field.
+func (r *queryResolver) SearchSymbols(ctx context.Context, query string) ([]*model.SymbolCandidate, error) {
+	candidates, err := r.symbolTracker.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return convertToSymbolCandidates(candidates), nil
+}
+
 // Notification is the resolver for the notification field.
 func (r *queryResolver) Notification(ctx context.Context) (*model.Notification, error) {
 	memberID, err := GetMemberID(ctx)
@@ -137,9 +318,11 @@ func (r *queryResolver) Notification(ctx context.Context) (*model.Notification,
 		})
 	}
 	return &model.Notification{
-		ID:      notifications[0].ID.String(),
-		Time:    notifications[0].Time.Hour,
-		Targets: targets,
+		ID:                  notifications[0].ID.String(),
+		Time:                notifications[0].Time.Hour,
+		Targets:             targets,
+		MovingAverageWindow: model.MovingAverageWindow(strings.ToUpper(string(notifications[0].Window))),
+		Channels:            convertToNotificationChannels(notifications[0].Channels),
 	}, nil
 }
 
@@ -148,32 +331,131 @@ func (r *queryResolver) Notifications(ctx context.Context) ([]*model.Notificatio
 	return nil, fmt.Errorf("not implement")
 }
 
+// Alerts is the resolver for the alerts field.
+func (r *queryResolver) Alerts(ctx context.Context) ([]*model.Alert, error) {
+	memberID, err := GetMemberID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := r.alertRepository.GetByMemberID(ctx, *memberID)
+	if err != nil {
+		return nil, err
+	}
+	return convertToAlerts(alerts), nil
+}
+
 // Detail is the resolver for the detail field.
-func (r *symbolResolver) Detail(ctx context.Context, obj *model.Symbol) (*model.SymbolDetail, error) {
-	return obj.Detail, nil
+func (r *symbolResolver) Detail(ctx context.Context, obj *model.Symbol, currency *model.Currency) (*model.SymbolDetail, error) {
+	if currency == nil || obj.Detail == nil || obj.Detail.Currency == nil || *currency == *obj.Detail.Currency {
+		return obj.Detail, nil
+	}
+	detail, err := r.loader.SymbolDetail.Load(ctx, obj.Symbol)()
+	if err != nil {
+		return nil, err
+	}
+	to, err := notify.ParseCurrency(string(*currency))
+	if err != nil {
+		return nil, err
+	}
+	converted, err := r.currencyConverter.ConvertSymbolDetail(ctx, *detail, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return convertToSymbolDetail(converted), nil
 }
 
 // Chart is the resolver for the chart field.
-func (r *symbolResolver) Chart(ctx context.Context, obj *model.Symbol, input model.ChartInput) ([]*model.Stock, error) {
+func (r *symbolResolver) Chart(ctx context.Context, obj *model.Symbol, input model.ChartInput, currency *model.Currency) ([]*model.Stock, error) {
 	if input.Symbol == nil {
 		return []*model.Stock{}, nil
 	}
 	if *input.Symbol != obj.Symbol {
 		return []*model.Stock{}, nil
 	}
+	var rates *notify.ExchangeRates
+	if currency != nil {
+		detail, err := r.loader.SymbolDetail.Load(ctx, obj.Symbol)()
+		if err != nil {
+			return nil, err
+		}
+		if detail.Currency == nil {
+			return nil, notify.NewValidationError("Unknown currency", fmt.Sprintf("currency of %s is unknown", obj.Symbol))
+		}
+		to, err := notify.ParseCurrency(string(*currency))
+		if err != nil {
+			return nil, err
+		}
+		rates, err = r.currencyConverter.Rates(ctx, *detail.Currency, to, input.Start, input.End)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if input.Interval != nil && *input.Interval != model.IntervalOneDay {
+		interval := notify.Interval(strings.ToLower(string(*input.Interval)))
+		stocks, err := r.intradayRepository.GetByPeriod(ctx, obj.Symbol, interval, input.Start, input.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get intraday stock by period: %w", err)
+		}
+		if rates != nil {
+			stocks = rates.ConvertIntradayStocks(stocks)
+		}
+		return convertToIntradayStocks(stocks), nil
+	}
 	stocks, err := r.stockRepository.GetStockByPeriod(ctx, obj.Symbol, input.Start, input.End)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stock by period: %w", err)
 	}
-	result := make([]*model.Stock, 0, len(stocks))
-	for _, stock := range stocks {
-		result = append(result, &model.Stock{
-			Symbol:    obj.Symbol,
-			Price:     stock.Close,
-			Timestamp: stock.Timestamp.Format("2006/01/02"),
-		})
+	if rates != nil {
+		stocks = rates.ConvertStocks(stocks)
+	}
+	return convertToStocks(stocks), nil
+}
+
+// Indicators is the resolver for the indicators field.
+func (r *symbolResolver) Indicators(ctx context.Context, obj *model.Symbol, input model.IndicatorInput) ([]*model.IndicatorPoint, error) {
+	params := notify.IndicatorParams{}
+	if input.Period != nil {
+		params.Period = int(*input.Period)
+	}
+	if input.FastPeriod != nil {
+		params.FastPeriod = int(*input.FastPeriod)
+	}
+	if input.SlowPeriod != nil {
+		params.SlowPeriod = int(*input.SlowPeriod)
+	}
+	if input.SignalPeriod != nil {
+		params.SignalPeriod = int(*input.SignalPeriod)
+	}
+	if input.StdDev != nil {
+		params.StdDev = decimal.NewFromFloat(*input.StdDev)
+	}
+	points, err := r.indicatorService.Calculate(
+		ctx, obj.Symbol,
+		notify.IndicatorKind(strings.ToLower(string(input.Kind))),
+		params, input.Start, input.End,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate indicator: %w", err)
+	}
+	return convertToIndicatorPoints(points), nil
+}
+
+// Dividends is the resolver for the dividends field.
+func (r *symbolResolver) Dividends(ctx context.Context, obj *model.Symbol) ([]*model.Dividend, error) {
+	dividends, err := r.corporateActionRepository.GetDividends(ctx, obj.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividends: %w", err)
+	}
+	return convertToDividends(dividends), nil
+}
+
+// Splits is the resolver for the splits field.
+func (r *symbolResolver) Splits(ctx context.Context, obj *model.Symbol) ([]*model.Split, error) {
+	splits, err := r.corporateActionRepository.GetSplits(ctx, obj.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get splits: %w", err)
 	}
-	return result, nil
+	return convertToSplits(splits), nil
 }
 
 // Mutation returns MutationResolver implementation.
@@ -14,18 +14,33 @@ import (
 func InitResolver(db *bun.DB) *Resolver {
 	wire.Build(
 		notify.InitStockRepository,
+		notify.InitIntradayRepository,
 		notify.InitNotificationRepository,
 		notify.InitSymbolRepository,
+		notify.InitCorporateActionRepository,
+		notify.InitTrackedSymbolRepository,
+		notify.NewHTTPClient,
+		notify.DefaultStockRegisterOption,
+		notify.DefaultSymbolTrackerOption,
+		notify.InitSymbolTracker,
 		notify.InitNotificationCreator,
+		notify.InitMemberRepository,
+		notify.InitNotificationDeliveryRepository,
+		notify.InitAlertRepository,
+		notify.InitAlertCreator,
+		notify.InitIndicatorService,
+		notify.InitCurrencyConverter,
 		notify.NewDataLoader,
 		NewResolver,
 	)
 	return &Resolver{}
 }
 
-func InitRootDirective(logger *slog.Logger) *DirectiveRoot {
+func InitRootDirective(logger *slog.Logger, db *bun.DB) *DirectiveRoot {
 	wire.Build(
+		notify.InitMemberRepository,
 		NewAuthDirective,
+		NewAdminDirective,
 		NewDirectiveRoot,
 	)
 	return &DirectiveRoot{}
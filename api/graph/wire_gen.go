@@ -16,16 +16,31 @@ import (
 
 func InitResolver(db *bun.DB) *Resolver {
 	stockRepository := notifystock.InitStockRepository(db)
+	intradayRepository := notifystock.InitIntradayRepository(db)
 	symbolRepository := notifystock.InitSymbolRepository(db)
+	corporateActionRepository := notifystock.InitCorporateActionRepository(db)
+	trackedSymbolRepository := notifystock.InitTrackedSymbolRepository(db)
+	httpClientInterface := notifystock.NewHTTPClient()
+	stockRegisterOption := notifystock.DefaultStockRegisterOption()
+	symbolTrackerOption := notifystock.DefaultSymbolTrackerOption()
+	symbolTracker := notifystock.InitSymbolTracker(db, httpClientInterface, stockRegisterOption, symbolTrackerOption)
 	notificationRepository := notifystock.InitNotificationRepository(db)
 	notificationCreator := notifystock.InitNotificationCreator(db)
+	memberRepository := notifystock.InitMemberRepository(db)
+	notificationDeliveryRepository := notifystock.InitNotificationDeliveryRepository(db)
+	alertRepository := notifystock.InitAlertRepository(db)
+	alertCreator := notifystock.InitAlertCreator(db)
+	indicatorService := notifystock.InitIndicatorService(db)
+	currencyConverter := notifystock.InitCurrencyConverter(db)
 	dataLoader := notifystock.NewDataLoader(symbolRepository)
-	resolver := NewResolver(stockRepository, symbolRepository, notificationRepository, notificationCreator, dataLoader)
+	resolver := NewResolver(stockRepository, intradayRepository, symbolRepository, corporateActionRepository, trackedSymbolRepository, symbolTracker, notificationRepository, notificationCreator, memberRepository, notificationDeliveryRepository, alertRepository, alertCreator, indicatorService, currencyConverter, dataLoader)
 	return resolver
 }
 
-func InitRootDirective(logger *slog.Logger) *DirectiveRoot {
+func InitRootDirective(logger *slog.Logger, db *bun.DB) *DirectiveRoot {
 	directive := NewAuthDirective(logger)
-	directiveRoot := NewDirectiveRoot(directive)
+	memberRepository := notifystock.InitMemberRepository(db)
+	adminDirective := NewAdminDirective(logger, memberRepository)
+	directiveRoot := NewDirectiveRoot(directive, adminDirective)
 	return directiveRoot
 }
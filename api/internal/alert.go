@@ -0,0 +1,536 @@
+package notifystock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+const (
+	// DefaultAlertCooldown is used when an alert is created without a cooldown.
+	DefaultAlertCooldown = 24 * time.Hour
+	// DefaultShortWindow and DefaultLongWindow are the moving average windows
+	// in trading days used when an alert does not set them.
+	DefaultShortWindow = 50
+	DefaultLongWindow  = 200
+)
+
+type AlertCondition string
+
+const (
+	// AlertConditionAbove triggers while the price is above the threshold.
+	AlertConditionAbove AlertCondition = "above"
+	// AlertConditionBelow triggers while the price is below the threshold.
+	AlertConditionBelow AlertCondition = "below"
+	// AlertConditionCrosses triggers when the price moves across the
+	// threshold since the previous close.
+	AlertConditionCrosses AlertCondition = "crosses"
+	// AlertConditionChangePercent triggers when the daily change exceeds
+	// the threshold percent in either direction.
+	AlertConditionChangePercent AlertCondition = "change_percent"
+	// AlertConditionGoldenCross triggers when the short moving average
+	// crosses above the long one.
+	AlertConditionGoldenCross AlertCondition = "golden_cross"
+	// AlertConditionDeathCross triggers when the short moving average
+	// crosses below the long one.
+	AlertConditionDeathCross AlertCondition = "death_cross"
+	// AlertConditionRatioBand triggers when the closing price to long moving
+	// average ratio, in percent, leaves the band between the threshold and
+	// the upper threshold.
+	AlertConditionRatioBand AlertCondition = "ratio_band"
+)
+
+func (c AlertCondition) IsValid() bool {
+	switch c {
+	case AlertConditionAbove, AlertConditionBelow, AlertConditionCrosses, AlertConditionChangePercent,
+		AlertConditionGoldenCross, AlertConditionDeathCross, AlertConditionRatioBand:
+		return true
+	}
+	return false
+}
+
+// UsesHistory reports whether the condition is evaluated over the daily
+// stocks instead of the latest quote.
+func (c AlertCondition) UsesHistory() bool {
+	switch c {
+	case AlertConditionGoldenCross, AlertConditionDeathCross, AlertConditionRatioBand:
+		return true
+	}
+	return false
+}
+
+type Alert struct {
+	bun.BaseModel `bun:"table:alerts"`
+
+	ID              uuid.UUID       `bun:"id,type:uuid,pk"`
+	MemberID        uuid.UUID       `bun:"member_id,type:uuid,notnull"`
+	Symbol          string          `bun:"symbol,type:text,notnull"`
+	Condition       AlertCondition  `bun:"condition,type:text,notnull"`
+	Threshold       decimal.Decimal `bun:"threshold,type:decimal,notnull"`
+	UpperThreshold  decimal.Decimal `bun:"upper_threshold,type:decimal,notnull"`
+	ShortWindow     int             `bun:"short_window,notnull"`
+	LongWindow      int             `bun:"long_window,notnull"`
+	CooldownMinutes int             `bun:"cooldown_minutes,notnull"`
+	Paused          bool            `bun:"paused,notnull"`
+	LastTriggeredAt time.Time       `bun:"last_triggered_at,type:timestamp,nullzero"`
+	CreatedAt       time.Time       `bun:"created_at,type:timestamp,notnull,default:current_timestamp"`
+}
+
+type AlertOption func(alert *Alert) *Alert
+
+// WithMovingAverageWindows sets the short and long moving average windows in
+// trading days. The ratio band only uses the long window.
+func WithMovingAverageWindows(short, long int) AlertOption {
+	return func(alert *Alert) *Alert {
+		alert.ShortWindow = short
+		alert.LongWindow = long
+		return alert
+	}
+}
+
+// WithUpperThreshold sets the upper bound of the ratio band.
+func WithUpperThreshold(upper decimal.Decimal) AlertOption {
+	return func(alert *Alert) *Alert {
+		alert.UpperThreshold = upper
+		return alert
+	}
+}
+
+func NewAlert(
+	ID *uuid.UUID, memberID uuid.UUID, symbol string,
+	condition AlertCondition, threshold decimal.Decimal, cooldown time.Duration,
+	opts ...AlertOption,
+) (*Alert, error) {
+	if !condition.IsValid() {
+		return nil, NewValidationError("Unsupported alert condition", fmt.Sprintf("condition %q is not supported", condition))
+	}
+	if cooldown < 0 {
+		return nil, NewValidationError("Invalid cooldown", "cooldown must not be negative")
+	}
+	if ID == nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		ID = &id
+	}
+	alert := &Alert{
+		ID:              *ID,
+		MemberID:        memberID,
+		Symbol:          symbol,
+		Condition:       condition,
+		Threshold:       threshold,
+		CooldownMinutes: int(cooldown.Minutes()),
+		CreatedAt:       time.Now(),
+	}
+	for _, opt := range opts {
+		alert = opt(alert)
+	}
+	if err := alert.validate(); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+func (a *Alert) validate() error {
+	switch a.Condition {
+	case AlertConditionGoldenCross, AlertConditionDeathCross:
+		if a.ShortWindow == 0 {
+			a.ShortWindow = DefaultShortWindow
+		}
+		if a.LongWindow == 0 {
+			a.LongWindow = DefaultLongWindow
+		}
+		if a.ShortWindow <= 0 || a.LongWindow <= a.ShortWindow {
+			return NewValidationError("Invalid moving average windows",
+				"short window must be greater than zero and less than long window")
+		}
+	case AlertConditionRatioBand:
+		if a.LongWindow == 0 {
+			a.LongWindow = DefaultLongWindow
+		}
+		if a.LongWindow <= 0 {
+			return NewValidationError("Invalid moving average windows", "long window must be greater than zero")
+		}
+		if !a.Threshold.IsPositive() || !a.UpperThreshold.GreaterThan(a.Threshold) {
+			return NewValidationError("Invalid ratio band",
+				"threshold must be greater than zero and less than upper threshold")
+		}
+	default:
+		if !a.Threshold.IsPositive() {
+			return NewValidationError("Invalid threshold", "threshold must be greater than zero")
+		}
+	}
+	return nil
+}
+
+func (a *Alert) Cooldown() time.Duration {
+	return time.Duration(a.CooldownMinutes) * time.Minute
+}
+
+func (a *Alert) InCooldown(now time.Time) bool {
+	if a.LastTriggeredAt.IsZero() {
+		return false
+	}
+	return now.Before(a.LastTriggeredAt.Add(a.Cooldown()))
+}
+
+// Triggered reports whether the alert fires for the latest quote and the
+// daily stocks of its symbol.
+func (a *Alert) Triggered(detail SymbolDetail, stocks *Stocks) (bool, error) {
+	if !a.Condition.UsesHistory() {
+		return a.Matches(detail.PreviousClose, detail.MarketPrice), nil
+	}
+	if stocks == nil {
+		return false, fmt.Errorf("no stocks for %s", a.Symbol)
+	}
+	switch a.Condition {
+	case AlertConditionGoldenCross, AlertConditionDeathCross:
+		crossover, err := stocks.Crossover(a.ShortWindow, a.LongWindow)
+		if err != nil {
+			return false, err
+		}
+		return string(crossover) == string(a.Condition), nil
+	case AlertConditionRatioBand:
+		var inBand [2]bool
+		for offset := range inBand {
+			ratio, err := stocks.PriceToMovingAverageRatio(a.LongWindow, offset)
+			if err != nil {
+				return false, err
+			}
+			percent := ratio.Mul(decimal.New(100, 0))
+			inBand[offset] = percent.GreaterThanOrEqual(a.Threshold) &&
+				percent.LessThanOrEqual(a.UpperThreshold)
+		}
+		return inBand[1] && !inBand[0], nil
+	}
+	return false, nil
+}
+
+// Matches reports whether the condition holds for the price moving from
+// previous to price.
+func (a *Alert) Matches(previous, price decimal.Decimal) bool {
+	switch a.Condition {
+	case AlertConditionAbove:
+		return price.GreaterThan(a.Threshold)
+	case AlertConditionBelow:
+		return price.LessThan(a.Threshold)
+	case AlertConditionCrosses:
+		return (previous.LessThan(a.Threshold) && price.GreaterThanOrEqual(a.Threshold)) ||
+			(previous.GreaterThan(a.Threshold) && price.LessThanOrEqual(a.Threshold))
+	case AlertConditionChangePercent:
+		if previous.IsZero() {
+			return false
+		}
+		change := price.Sub(previous).Div(previous).Mul(decimal.New(100, 0))
+		return change.Abs().GreaterThanOrEqual(a.Threshold)
+	}
+	return false
+}
+
+func (a *Alert) Describe() string {
+	switch a.Condition {
+	case AlertConditionChangePercent:
+		return fmt.Sprintf("%s moved more than %s%%", a.Symbol, a.Threshold.String())
+	case AlertConditionCrosses:
+		return fmt.Sprintf("%s crossed %s", a.Symbol, a.Threshold.String())
+	case AlertConditionGoldenCross:
+		return fmt.Sprintf("%s %d-day MA crossed above %d-day MA", a.Symbol, a.ShortWindow, a.LongWindow)
+	case AlertConditionDeathCross:
+		return fmt.Sprintf("%s %d-day MA crossed below %d-day MA", a.Symbol, a.ShortWindow, a.LongWindow)
+	case AlertConditionRatioBand:
+		return fmt.Sprintf("%s price to %d-day MA ratio left %s%%-%s%%",
+			a.Symbol, a.LongWindow, a.Threshold.String(), a.UpperThreshold.String())
+	default:
+		return fmt.Sprintf("%s is %s %s", a.Symbol, a.Condition, a.Threshold.String())
+	}
+}
+
+type AlertRepository struct {
+	db *bun.DB
+}
+
+func NewAlertRepository(db *bun.DB) *AlertRepository {
+	return &AlertRepository{
+		db: db,
+	}
+}
+
+func (r *AlertRepository) Save(ctx context.Context, alerts []*Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&alerts).
+		On("CONFLICT (id) DO UPDATE").
+		Set(strings.Join([]string{
+			"condition = EXCLUDED.condition",
+			"threshold = EXCLUDED.threshold",
+			"upper_threshold = EXCLUDED.upper_threshold",
+			"short_window = EXCLUDED.short_window",
+			"long_window = EXCLUDED.long_window",
+			"cooldown_minutes = EXCLUDED.cooldown_minutes",
+			"paused = EXCLUDED.paused",
+			"last_triggered_at = EXCLUDED.last_triggered_at",
+		}, ",")).
+		Exec(ctx)
+	return err
+}
+
+func (r *AlertRepository) GetByMemberID(ctx context.Context, memberID uuid.UUID) ([]*Alert, error) {
+	var alerts []*Alert
+	err := r.db.NewSelect().
+		Model(&alerts).
+		Where("member_id = ?", memberID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *AlertRepository) GetActiveBySymbols(ctx context.Context, symbols []string) ([]*Alert, error) {
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	var alerts []*Alert
+	err := r.db.NewSelect().
+		Model(&alerts).
+		Where("symbol IN (?)", bun.In(symbols)).
+		Where("paused = FALSE").
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *AlertRepository) SetPaused(
+	ctx context.Context, memberID, id uuid.UUID, paused bool,
+) (*Alert, error) {
+	var alert Alert
+	_, err := r.db.NewUpdate().
+		Model(&alert).
+		Set("paused = ?", paused).
+		Where("id = ?", id).
+		Where("member_id = ?", memberID).
+		Returning("*").
+		Exec(ctx, &alert)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *AlertRepository) Delete(ctx context.Context, memberID, id uuid.UUID) error {
+	res, err := r.db.NewDelete().
+		Model((*Alert)(nil)).
+		Where("id = ?", id).
+		Where("member_id = ?", memberID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return NewNotFoundError("Alert")
+	}
+	return nil
+}
+
+type AlertCreator struct {
+	alertRepository  *AlertRepository
+	symbolRepository *SymbolRepository
+}
+
+func NewAlertCreator(
+	alertRepository *AlertRepository,
+	symbolRepository *SymbolRepository,
+) *AlertCreator {
+	return &AlertCreator{
+		alertRepository:  alertRepository,
+		symbolRepository: symbolRepository,
+	}
+}
+
+func (c *AlertCreator) Create(
+	ctx context.Context, memberID uuid.UUID, symbol string,
+	condition AlertCondition, threshold decimal.Decimal, cooldown time.Duration,
+	opts ...AlertOption,
+) (*Alert, error) {
+	if _, err := c.symbolRepository.Get(ctx, symbol); err != nil {
+		return nil, fmt.Errorf("unsupported symbol: %s: %w", symbol, err)
+	}
+	alert, err := NewAlert(nil, memberID, symbol, condition, threshold, cooldown, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.alertRepository.Save(ctx, []*Alert{alert}); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// AlertEvaluator checks active alerts against the latest prices and queues a
+// mail for every alert that triggers.
+type AlertEvaluator struct {
+	alertRepository  *AlertRepository
+	stockRepository  *StockRepository
+	symbolRepository *SymbolRepository
+	memberRepository *MemberRepository
+	outboxRepository *OutboxRepository
+}
+
+func NewAlertEvaluator(
+	alertRepository *AlertRepository,
+	stockRepository *StockRepository,
+	symbolRepository *SymbolRepository,
+	memberRepository *MemberRepository,
+	outboxRepository *OutboxRepository,
+) *AlertEvaluator {
+	return &AlertEvaluator{
+		alertRepository:  alertRepository,
+		stockRepository:  stockRepository,
+		symbolRepository: symbolRepository,
+		memberRepository: memberRepository,
+		outboxRepository: outboxRepository,
+	}
+}
+
+// Evaluate returns the alerts triggered at now.
+func (e *AlertEvaluator) Evaluate(ctx context.Context, symbols []string, now time.Time) ([]*Alert, error) {
+	alerts, err := e.alertRepository.GetActiveBySymbols(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	window := 1
+	for _, alert := range alerts {
+		window = max(window, alert.LongWindow)
+	}
+	prices, stocks, err := e.market(ctx, symbols, window+1, now)
+	if err != nil {
+		return nil, err
+	}
+
+	triggered := make([]*Alert, 0)
+	for _, alert := range alerts {
+		detail, ok := prices[alert.Symbol]
+		if !ok || alert.InCooldown(now) {
+			continue
+		}
+		fired, err := alert.Triggered(detail, stocks[alert.Symbol])
+		if err != nil {
+			logger.Warn("failed to evaluate alert", "alert_id", alert.ID, "error", err)
+			continue
+		}
+		if fired {
+			triggered = append(triggered, alert)
+		}
+	}
+	if len(triggered) == 0 {
+		return nil, nil
+	}
+	if err := e.notify(ctx, triggered, prices, now); err != nil {
+		return nil, err
+	}
+	return triggered, nil
+}
+
+// market returns the latest detail and at least the last window trading days
+// of each symbol. When the quote has no market price the closes of the stored
+// daily rows are used instead.
+func (e *AlertEvaluator) market(
+	ctx context.Context, symbols []string, window int, now time.Time,
+) (map[string]SymbolDetail, map[string]*Stocks, error) {
+	details, err := e.symbolRepository.GetBySymbols(ctx, symbols)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := e.stockRepository.GetStockByPeriodAndSymbols(
+		ctx, symbols, lookbackSince(now, window), now,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	prices := make(map[string]SymbolDetail, len(details))
+	stocks := make(map[string]*Stocks, len(details))
+	for _, detail := range details {
+		daily := rows[detail.Symbol]
+		if detail.MarketPrice.IsZero() && len(daily) > 0 {
+			detail.MarketPrice = decimal.NewFromFloat(daily[len(daily)-1].Close)
+			if len(daily) > 1 {
+				detail.PreviousClose = decimal.NewFromFloat(daily[len(daily)-2].Close)
+			}
+		}
+		prices[detail.Symbol] = detail
+		if stocks[detail.Symbol], err = NewStocks(detail, daily); err != nil {
+			return nil, nil, err
+		}
+	}
+	return prices, stocks, nil
+}
+
+func (e *AlertEvaluator) notify(
+	ctx context.Context, alerts []*Alert, prices map[string]SymbolDetail, now time.Time,
+) error {
+	memberIDs := make([]uuid.UUID, 0, len(alerts))
+	for _, alert := range alerts {
+		memberIDs = append(memberIDs, alert.MemberID)
+	}
+	members, err := e.memberRepository.GetByIDs(ctx, memberIDs)
+	if err != nil {
+		return err
+	}
+	emails := make(map[uuid.UUID]string, len(members))
+	for _, member := range members {
+		emails[member.ID] = member.Email()
+	}
+
+	var errs []error
+	messages := make([]*OutboxMessage, 0, len(alerts))
+	for _, alert := range alerts {
+		email := emails[alert.MemberID]
+		if email == "" {
+			logger.Warn("member has no email address", "member_id", alert.MemberID, "alert_id", alert.ID)
+			continue
+		}
+		detail := prices[alert.Symbol]
+		lines := []string{
+			alert.Describe(),
+			fmt.Sprintf("Price: %s", detail.MarketPrice.String()),
+		}
+		if !detail.PreviousClose.IsZero() {
+			lines = append(lines, fmt.Sprintf("Change: %s (%s)", detail.Change(), detail.ChangePercent()))
+		}
+		text := strings.Join(lines, "\n")
+		message, err := NewOutboxMessage(
+			nil, nil, DeliveryChannelEmail, Cfg.FROM, email,
+			fmt.Sprintf("Alert: %s", alert.Describe()), text,
+		)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		messages = append(messages, message)
+		alert.LastTriggeredAt = now
+	}
+	if err := e.outboxRepository.Save(ctx, messages); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	if err := e.alertRepository.Save(ctx, alerts); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	return errors.Join(errs...)
+}
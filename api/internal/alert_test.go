@@ -0,0 +1,227 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestAlertMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition notify.AlertCondition
+		threshold int64
+		previous  int64
+		price     int64
+		want      bool
+	}{
+		{"above", notify.AlertConditionAbove, 40000, 39000, 40100, true},
+		{"not above", notify.AlertConditionAbove, 40000, 39000, 40000, false},
+		{"below", notify.AlertConditionBelow, 40000, 41000, 39900, true},
+		{"not below", notify.AlertConditionBelow, 40000, 39000, 40000, false},
+		{"crosses upward", notify.AlertConditionCrosses, 40000, 39900, 40000, true},
+		{"crosses downward", notify.AlertConditionCrosses, 40000, 40100, 39900, true},
+		{"stays above", notify.AlertConditionCrosses, 40000, 40100, 40200, false},
+		{"rises beyond percent", notify.AlertConditionChangePercent, 3, 1000, 1030, true},
+		{"falls beyond percent", notify.AlertConditionChangePercent, 3, 1000, 960, true},
+		{"within percent", notify.AlertConditionChangePercent, 3, 1000, 1020, false},
+		{"no previous close", notify.AlertConditionChangePercent, 3, 0, 1020, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert, err := notify.NewAlert(nil, uuid.New(), "^N225",
+				tt.condition, decimal.NewFromInt(tt.threshold), time.Hour)
+			assert.NoError(t, err)
+
+			got := alert.Matches(decimal.NewFromInt(tt.previous), decimal.NewFromInt(tt.price))
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAlertTriggered(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition notify.AlertCondition
+		threshold int64
+		opts      []notify.AlertOption
+		closes    []float64
+		want      bool
+	}{
+		{"golden cross", notify.AlertConditionGoldenCross, 0,
+			[]notify.AlertOption{notify.WithMovingAverageWindows(1, 3)},
+			[]float64{10, 10, 9, 8, 14}, true},
+		{"no death cross on golden cross", notify.AlertConditionDeathCross, 0,
+			[]notify.AlertOption{notify.WithMovingAverageWindows(1, 3)},
+			[]float64{10, 10, 9, 8, 14}, false},
+		{"leave ratio band", notify.AlertConditionRatioBand, 90,
+			[]notify.AlertOption{notify.WithMovingAverageWindows(0, 3), notify.WithUpperThreshold(decimal.NewFromInt(110))},
+			[]float64{100, 100, 100, 100, 130}, true},
+		{"stay outside ratio band", notify.AlertConditionRatioBand, 90,
+			[]notify.AlertOption{notify.WithMovingAverageWindows(0, 3), notify.WithUpperThreshold(decimal.NewFromInt(110))},
+			[]float64{100, 100, 100, 130, 150}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert, err := notify.NewAlert(nil, uuid.New(), "N225",
+				tt.condition, decimal.NewFromInt(tt.threshold), time.Hour, tt.opts...)
+			assert.NoError(t, err)
+			stocks, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(tt.closes...))
+			assert.NoError(t, err)
+
+			got, err := alert.Triggered(notify.SymbolDetail{}, stocks)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("not enough stocks", func(t *testing.T) {
+		alert, err := notify.NewAlert(nil, uuid.New(), "N225",
+			notify.AlertConditionGoldenCross, decimal.Zero, time.Hour)
+		assert.NoError(t, err)
+		stocks, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(1, 2, 3))
+		assert.NoError(t, err)
+
+		_, err = alert.Triggered(notify.SymbolDetail{}, stocks)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNewAlert(t *testing.T) {
+	t.Run("reject unsupported condition", func(t *testing.T) {
+		_, err := notify.NewAlert(nil, uuid.New(), "^N225",
+			notify.AlertCondition("between"), decimal.NewFromInt(1), time.Hour)
+		assert.Error(t, err)
+	})
+	t.Run("reject non positive threshold", func(t *testing.T) {
+		_, err := notify.NewAlert(nil, uuid.New(), "^N225",
+			notify.AlertConditionAbove, decimal.Zero, time.Hour)
+		assert.Error(t, err)
+	})
+	t.Run("default moving average windows", func(t *testing.T) {
+		alert, err := notify.NewAlert(nil, uuid.New(), "^N225",
+			notify.AlertConditionGoldenCross, decimal.Zero, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, notify.DefaultShortWindow, alert.ShortWindow)
+		assert.Equal(t, notify.DefaultLongWindow, alert.LongWindow)
+	})
+	t.Run("reject short window not less than long window", func(t *testing.T) {
+		_, err := notify.NewAlert(nil, uuid.New(), "^N225",
+			notify.AlertConditionDeathCross, decimal.Zero, time.Hour,
+			notify.WithMovingAverageWindows(200, 50))
+		assert.Error(t, err)
+	})
+	t.Run("reject inverted ratio band", func(t *testing.T) {
+		_, err := notify.NewAlert(nil, uuid.New(), "^N225",
+			notify.AlertConditionRatioBand, decimal.NewFromInt(110), time.Hour,
+			notify.WithUpperThreshold(decimal.NewFromInt(90)))
+		assert.Error(t, err)
+	})
+	t.Run("cooldown", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)
+		alert, err := notify.NewAlert(nil, uuid.New(), "^N225",
+			notify.AlertConditionAbove, decimal.NewFromInt(1), time.Hour)
+		assert.NoError(t, err)
+		assert.False(t, alert.InCooldown(now))
+
+		alert.LastTriggeredAt = now
+		assert.True(t, alert.InCooldown(now.Add(59*time.Minute)))
+		assert.False(t, alert.InCooldown(now.Add(time.Hour)))
+	})
+}
+
+func TestAlertEvaluator(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	now := time.Now().UTC()
+
+	memberRepository := notify.NewMemberRepository(db)
+	symbolRepository := notify.NewSymbolRepository(db)
+	stockRepository := notify.NewStockRepository(db)
+	alertRepository := notify.NewAlertRepository(db)
+	outboxRepository := notify.NewOutboxRepository(db)
+
+	symbol := notify.NewSymbolDetail("N225", "NIKKEI 225", "NIKKEI", "JPY", decimal.NewFromInt(40100), decimal.NewFromInt(39900))
+	if err := symbolRepository.Save(ctx, []notify.SymbolDetail{*symbol}); err != nil {
+		panic(err)
+	}
+	member, err := notify.NewGoogleMember(nil, "alert@example.com", "alert@example.com", true, "Name", "Given", "Family", "Picture")
+	assert.NoError(t, err)
+	assert.NoError(t, memberRepository.Save(ctx, []*notify.Member{member}))
+
+	evaluator := notify.NewAlertEvaluator(
+		alertRepository, stockRepository, symbolRepository, memberRepository, outboxRepository,
+	)
+	creator := notify.NewAlertCreator(alertRepository, symbolRepository)
+
+	t.Run("trigger once within cooldown", func(t *testing.T) {
+		crosses, err := creator.Create(ctx, member.ID, "N225",
+			notify.AlertConditionCrosses, decimal.NewFromInt(40000), time.Hour)
+		assert.NoError(t, err)
+		below, err := creator.Create(ctx, member.ID, "N225",
+			notify.AlertConditionBelow, decimal.NewFromInt(40000), time.Hour)
+		assert.NoError(t, err)
+
+		triggered, err := evaluator.Evaluate(ctx, []string{"N225"}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(triggered))
+		assert.Equal(t, crosses.ID, triggered[0].ID)
+
+		messages, err := outboxRepository.GetByStatus(ctx, notify.OutboxStatusPending)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(messages))
+		assert.Equal(t, "alert@example.com", messages[0].To)
+		assert.Contains(t, messages[0].Text, "N225 crossed 40000")
+
+		triggered, err = evaluator.Evaluate(ctx, []string{"N225"}, now.Add(30*time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(triggered))
+
+		alerts, err := alertRepository.GetByMemberID(ctx, member.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(alerts))
+		assert.Equal(t, below.ID, alerts[1].ID)
+		assert.True(t, alerts[1].LastTriggeredAt.IsZero())
+	})
+
+	t.Run("skip paused alerts", func(t *testing.T) {
+		alert, err := creator.Create(ctx, member.ID, "N225",
+			notify.AlertConditionAbove, decimal.NewFromInt(40000), 0)
+		assert.NoError(t, err)
+		paused, err := alertRepository.SetPaused(ctx, member.ID, alert.ID, true)
+		assert.NoError(t, err)
+		assert.True(t, paused.Paused)
+
+		triggered, err := evaluator.Evaluate(ctx, []string{"N225"}, now.Add(2*time.Hour))
+		assert.NoError(t, err)
+		for _, a := range triggered {
+			assert.NotEqual(t, alert.ID, a.ID)
+		}
+	})
+
+	t.Run("delete only own alerts", func(t *testing.T) {
+		alert, err := creator.Create(ctx, member.ID, "N225",
+			notify.AlertConditionAbove, decimal.NewFromInt(50000), 0)
+		assert.NoError(t, err)
+
+		err = alertRepository.Delete(ctx, uuid.New(), alert.ID)
+		assert.Error(t, err)
+		err = alertRepository.Delete(ctx, member.ID, alert.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reject unsupported symbol", func(t *testing.T) {
+		_, err := creator.Create(ctx, member.ID, "UNKNOWN",
+			notify.AlertConditionAbove, decimal.NewFromInt(1), 0)
+		assert.Error(t, err)
+	})
+}
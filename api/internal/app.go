@@ -5,41 +5,109 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+type StockRegisterOption struct {
+	// Concurrency is the number of symbols fetched at the same time.
+	Concurrency int
+	// Timeout bounds the fetch and save of each symbol.
+	Timeout time.Duration
+}
+
+func DefaultStockRegisterOption() StockRegisterOption {
+	return StockRegisterOption{
+		Concurrency: 4,
+		Timeout:     30 * time.Second,
+	}
+}
+
 type StockRegister struct {
-	client           *FinanceClient
-	stockRepository  *StockRepository
-	symbolRepository *SymbolRepository
+	provider                  MarketDataProvider
+	stockRepository           *StockRepository
+	symbolRepository          *SymbolRepository
+	corporateActionRepository *CorporateActionRepository
+	trackedSymbolRepository   *TrackedSymbolRepository
+	option                    StockRegisterOption
 }
 
 func NewStockRegister(
-	client *FinanceClient,
+	provider MarketDataProvider,
 	stockRepository *StockRepository,
 	symbolRepository *SymbolRepository,
+	corporateActionRepository *CorporateActionRepository,
+	trackedSymbolRepository *TrackedSymbolRepository,
+	option StockRegisterOption,
 ) *StockRegister {
 	return &StockRegister{
-		client:           client,
-		stockRepository:  stockRepository,
-		symbolRepository: symbolRepository,
+		provider:                  provider,
+		stockRepository:           stockRepository,
+		symbolRepository:          symbolRepository,
+		corporateActionRepository: corporateActionRepository,
+		trackedSymbolRepository:   trackedSymbolRepository,
+		option:                    option,
 	}
 }
 
+// TrackedSymbols returns the enabled symbols of the registry, the ones of the
+// highest priority first.
+func (s *StockRegister) TrackedSymbols(ctx context.Context) ([]string, error) {
+	return s.trackedSymbolRepository.EnabledSymbols(ctx)
+}
+
 func (s *StockRegister) RegisterStockBySymbol(
 	ctx context.Context, symbol string, start, end time.Time) error {
-	stock, err := s.client.FetchStock(
-		symbol,
-		start,
-		end,
-		WithInterval("1d"),
-	)
+	return s.register(ctx, symbol, start, end, true)
+}
+
+// RegisterHistoryBySymbol registers the prices and the corporate actions of
+// the period but leaves the symbol detail alone, since its market price and
+// previous close only hold for a period ending now.
+func (s *StockRegister) RegisterHistoryBySymbol(
+	ctx context.Context, symbol string, start, end time.Time) error {
+	return s.register(ctx, symbol, start, end, false)
+}
+
+func (s *StockRegister) register(
+	ctx context.Context, symbol string, start, end time.Time, saveDetail bool) error {
+	if s.option.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.option.Timeout)
+		defer cancel()
+	}
+	fetchedAt := time.Now()
+	stock, err := s.provider.FetchStock(ctx, symbol, start, end)
 	if err != nil {
 		return err
 	}
+	for i := range stock.stocks {
+		stock.stocks[i].FetchedAt = fetchedAt
+	}
+	if err := s.corporateActionRepository.SaveDividends(ctx, stock.dividends); err != nil {
+		return err
+	}
+	if err := s.corporateActionRepository.SaveSplits(ctx, stock.splits); err != nil {
+		return err
+	}
+	// The fetched prices are already adjusted for the splits, so the stored
+	// ones fetched before a new split are adjusted before the fetched ones
+	// overwrite them.
+	adjusted, err := s.corporateActionRepository.AdjustSplits(ctx, symbol, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, split := range adjusted {
+		logger.Info("adjusted prices for split", "symbol", symbol, "date", split.Date, "split", split.String())
+	}
 	if err := s.stockRepository.Save(ctx, stock.stocks); err != nil {
 		return err
 	}
+	if !saveDetail {
+		return nil
+	}
 	if err := s.symbolRepository.Save(
 		ctx, []SymbolDetail{stock.symbol},
 	); err != nil {
@@ -47,86 +115,455 @@ func (s *StockRegister) RegisterStockBySymbol(
 	}
 	return nil
 }
+
+// LookbackStart returns the first of the last days trading days of the
+// symbols, counted on the calendars of their exchanges, so that the period
+// covers as many sessions across long holidays. Symbols not registered yet
+// are counted on weekdays.
+func (s *StockRegister) LookbackStart(
+	ctx context.Context, symbols []string, days int, now time.Time) (time.Time, error) {
+	details, err := s.symbolRepository.GetBySymbols(ctx, symbols)
+	if err != nil {
+		return time.Time{}, err
+	}
+	calendars := make(map[string]TradingCalendar, len(details))
+	for _, detail := range details {
+		calendars[detail.Symbol] = detail.Calendar()
+	}
+	start := now
+	for _, symbol := range symbols {
+		calendar, ok := calendars[symbol]
+		if !ok {
+			calendar = WeekdayCalendar{}
+		}
+		if day := TradingDaysAgo(calendar, now, days); day.Before(start) {
+			start = day
+		}
+	}
+	return start, nil
+}
+
+// RegisterStockBySymbols registers the symbols on a pool of workers. The
+// returned error joins a SymbolError for every symbol that failed, or was
+// not started before ctx was canceled, in the order of symbols.
 func (s *StockRegister) RegisterStockBySymbols(
 	ctx context.Context, symbols []string, start, end time.Time) error {
-	var errs []error
-	for _, symbol := range symbols {
-		if err := s.RegisterStockBySymbol(
-			ctx,
-			symbol,
-			start,
-			end,
-		); err != nil {
-			errs = append(errs, err)
+	errs := make([]error, len(symbols))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(s.option.Concurrency, 1), len(symbols)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := s.RegisterStockBySymbol(ctx, symbols[i], start, end); err != nil {
+					errs[i] = &SymbolError{Symbol: symbols[i], Err: err}
+				}
+			}
+		}()
+	}
+	sent := 0
+feed:
+	for ; sent < len(symbols); sent++ {
+		select {
+		case jobs <- sent:
+		case <-ctx.Done():
+			break feed
 		}
 	}
+	close(jobs)
+	wg.Wait()
+	for i := sent; i < len(symbols); i++ {
+		errs[i] = &SymbolError{Symbol: symbols[i], Err: ctx.Err()}
+	}
 	return errors.Join(errs...)
 }
 
+// SymbolError is the failure to register a symbol.
+type SymbolError struct {
+	Symbol string
+	Err    error
+}
+
+func (e *SymbolError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Symbol, e.Err)
+}
+
+func (e *SymbolError) Unwrap() error {
+	return e.Err
+}
+
+// FailedSymbols returns the symbols of the SymbolErrors joined in err.
+func FailedSymbols(err error) []string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var symbols []string
+		for _, e := range joined.Unwrap() {
+			symbols = append(symbols, FailedSymbols(e)...)
+		}
+		return symbols
+	}
+	var symbolErr *SymbolError
+	if errors.As(err, &symbolErr) {
+		return []string{symbolErr.Symbol}
+	}
+	return nil
+}
+
+// Mail is a message with a plain text body and an optional HTML
+// alternative.
+type Mail struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Inline are the images the HTML refers to.
+	Inline []InlineImage
+}
+
+// InlineImage is an image attached to an HTML mail, which the HTML refers to
+// as cid:Name.
+type InlineImage struct {
+	Name string `json:"name"`
+	Data []byte `json:"data"`
+}
+
 type MailService interface {
-	Send(from, to, subject, text string) error
+	// Send delivers the mail and returns the provider's message ID.
+	Send(mail Mail) (string, error)
 }
 
-type StockNotifier struct {
-	mailService      MailService
+type MarketSummary struct {
+	Subject string
+	Text    string
+	HTML    string
+	Images  []InlineImage
+}
+
+type MarketSummaryGenerator struct {
 	stockRepository  *StockRepository
 	symbolRepository *SymbolRepository
 }
 
-func NewStockNotifier(
-	mailService MailService,
+func NewMarketSummaryGenerator(
 	stockRepository *StockRepository,
 	symbolRepository *SymbolRepository,
-) *StockNotifier {
-	return &StockNotifier{
-		mailService:      mailService,
+) *MarketSummaryGenerator {
+	return &MarketSummaryGenerator{
 		stockRepository:  stockRepository,
 		symbolRepository: symbolRepository,
 	}
 }
 
-func (n *StockNotifier) Notify(symbols []string) error {
-	ctx := context.Background()
-	symbolDetails, err := n.symbolRepository.GetBySymbols(
+func (g *MarketSummaryGenerator) Generate(
+	ctx context.Context, symbols []string, window MovingAverageWindow, locale Locale, now time.Time,
+) (*MarketSummary, error) {
+	if !window.IsValid() {
+		return nil, fmt.Errorf("unsupported moving average window: %q", window)
+	}
+	symbolDetails, err := g.symbolRepository.GetBySymbols(
 		ctx, symbols,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	now := time.Now()
-	stocks, err := n.stockRepository.GetStockByPeriodAndSymbols(
-		ctx, symbols, now.AddDate(-1, 0, 0), now,
+	stocks, err := g.stockRepository.GetStockByPeriodAndSymbols(
+		ctx, symbols, window.Since(now), now,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	results := make([]*Stocks, 0, len(symbols))
 	for _, detail := range symbolDetails {
 		stock, ok := stocks[detail.Symbol]
 		if !ok {
-			return fmt.Errorf("symbol %s not found", detail.Symbol)
+			return nil, fmt.Errorf("symbol %s not found", detail.Symbol)
 		}
 		result, err := NewStocks(detail, stock)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		results = append(results, result)
 	}
 
-	subject := fmt.Sprintf("Market Summary %s", now.Format("January 02 2006"))
 	text := make([]string, 0)
+	rows := make([]SummaryRow, 0, len(results))
 	for _, result := range results {
-		message, err := result.GenerateNotificationMessage()
+		message, err := result.GenerateNotificationMessage(window, locale)
 		if err != nil {
 			logger.Error("failed to generate notification message", "error", err)
 			continue
 		}
+		row, err := result.SummaryRow(window, locale)
+		if err != nil {
+			logger.Error("failed to generate summary row", "error", err)
+			continue
+		}
 		text = append(text, message)
+		rows = append(rows, row)
 	}
+	subject := locale.T("summary.subject", locale.FormatDate(now))
+	html, images, err := RenderSummaryHTML(subject, rows, locale)
+	if err != nil {
+		return nil, err
+	}
+	return &MarketSummary{
+		Subject: subject,
+		Text:    strings.Join(text, "\n\n"),
+		HTML:    html,
+		Images:  images,
+	}, nil
+}
 
-	if err := n.mailService.Send(Cfg.FROM, Cfg.TO, subject, strings.Join(text, "\n\n")); err != nil {
+type StockNotifier struct {
+	summaryGenerator *MarketSummaryGenerator
+	outboxRepository *OutboxRepository
+	symbolRepository *SymbolRepository
+}
+
+func NewStockNotifier(
+	summaryGenerator *MarketSummaryGenerator,
+	outboxRepository *OutboxRepository,
+	symbolRepository *SymbolRepository,
+) *StockNotifier {
+	return &StockNotifier{
+		summaryGenerator: summaryGenerator,
+		outboxRepository: outboxRepository,
+		symbolRepository: symbolRepository,
+	}
+}
+
+// summaryInterval is how often the summary is sent. The summary is skipped
+// when no exchange of the symbols closed a session since the last one.
+const summaryInterval = 24 * time.Hour
+
+// Notify queues the market summary of symbols in the mail outbox.
+func (n *StockNotifier) Notify(symbols []string) error {
+	ctx := context.Background()
+	now := time.Now()
+	details, err := n.symbolRepository.GetBySymbols(ctx, symbols)
+	if err != nil {
 		return err
 	}
-	return nil
+	if !TradedSince(details, now.Add(-summaryInterval), now) {
+		logger.Info("no session closed since the last summary", "symbols", symbols)
+		return nil
+	}
+	summary, err := n.summaryGenerator.Generate(ctx, symbols, DefaultMovingAverageWindow, DefaultLocale, now)
+	if err != nil {
+		return err
+	}
+	message, err := NewOutboxMessage(
+		nil, nil, DeliveryChannelEmail, Cfg.FROM, Cfg.TO, summary.Subject, summary.Text,
+	)
+	if err != nil {
+		return err
+	}
+	message.HTML = summary.HTML
+	message.Inline = summary.Images
+	return n.outboxRepository.Save(ctx, []*OutboxMessage{message})
+}
+
+// NotificationDispatcher queues the market summary of every notification
+// scheduled for the current hour for the member who owns it.
+type NotificationDispatcher struct {
+	summaryGenerator       *MarketSummaryGenerator
+	outboxRepository       *OutboxRepository
+	notificationRepository *NotificationRepository
+	memberRepository       *MemberRepository
+	deliveryRepository     *NotificationDeliveryRepository
+	symbolRepository       *SymbolRepository
+}
+
+func NewNotificationDispatcher(
+	summaryGenerator *MarketSummaryGenerator,
+	outboxRepository *OutboxRepository,
+	notificationRepository *NotificationRepository,
+	memberRepository *MemberRepository,
+	deliveryRepository *NotificationDeliveryRepository,
+	symbolRepository *SymbolRepository,
+) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		summaryGenerator:       summaryGenerator,
+		outboxRepository:       outboxRepository,
+		notificationRepository: notificationRepository,
+		memberRepository:       memberRepository,
+		deliveryRepository:     deliveryRepository,
+		symbolRepository:       symbolRepository,
+	}
+}
+
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, now time.Time) error {
+	slot := NewDeliverySlot(now)
+	notifications, err := d.notificationRepository.GetByHour(ctx, NewTimeOfHour(slot))
+	if err != nil {
+		return err
+	}
+	if len(notifications) == 0 {
+		logger.Info("no notification to dispatch", "slot", slot)
+		return nil
+	}
+	memberIDs := make([]uuid.UUID, 0, len(notifications))
+	for _, notification := range notifications {
+		memberIDs = append(memberIDs, notification.MemberID)
+	}
+	members, err := d.memberRepository.GetByIDs(ctx, memberIDs)
+	if err != nil {
+		return err
+	}
+	memberByID := make(map[uuid.UUID]*Member, len(members))
+	for _, member := range members {
+		memberByID[member.ID] = member
+	}
+	var symbols []string
+	for _, notification := range notifications {
+		symbols = append(symbols, notification.Symbols()...)
+	}
+	details, err := d.symbolRepository.GetBySymbols(ctx, symbols)
+	if err != nil {
+		return err
+	}
+	detailBySymbol := make(map[string]SymbolDetail, len(details))
+	for _, detail := range details {
+		detailBySymbol[detail.Symbol] = detail
+	}
+
+	var errs []error
+	for _, notification := range notifications {
+		member, ok := memberByID[notification.MemberID]
+		if !ok {
+			logger.Warn("member not found",
+				"member_id", notification.MemberID, "notification_id", notification.ID)
+			continue
+		}
+		targets := make([]SymbolDetail, 0, len(notification.Targets))
+		for _, symbol := range notification.Symbols() {
+			if detail, ok := detailBySymbol[symbol]; ok {
+				targets = append(targets, detail)
+			}
+		}
+		if !TradedSince(targets, now.Add(-summaryInterval), now) {
+			logger.Info("no session closed since the last summary",
+				"notification_id", notification.ID, "symbols", notification.Symbols())
+			continue
+		}
+		destinations := notificationDestinations(notification, member.Email())
+		if len(destinations) == 0 {
+			logger.Warn("notification has no destination",
+				"member_id", notification.MemberID, "notification_id", notification.ID)
+			continue
+		}
+		if err := d.deliver(ctx, notification, destinations, member.Locale, now); err != nil {
+			errs = append(errs, fmt.Errorf("notification %s: %w", notification.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notificationDestinations resolves where the notification is sent. Email
+// channels and notifications without any channel are sent to the member's
+// address.
+func notificationDestinations(notification Notification, email string) []NotificationChannelSetting {
+	if len(notification.Channels) == 0 {
+		notification.Channels = []*NotificationChannel{{Kind: DeliveryChannelEmail}}
+	}
+	destinations := make([]NotificationChannelSetting, 0, len(notification.Channels))
+	for _, channel := range notification.Channels {
+		destination := channel.Destination
+		if channel.Kind == DeliveryChannelEmail {
+			destination = email
+		}
+		if destination == "" {
+			logger.Warn("member has no email address",
+				"member_id", notification.MemberID, "notification_id", notification.ID)
+			continue
+		}
+		destinations = append(destinations, NotificationChannelSetting{
+			Kind:        channel.Kind,
+			Destination: destination,
+		})
+	}
+	return destinations
+}
+
+func (d *NotificationDispatcher) deliver(
+	ctx context.Context, notification Notification,
+	destinations []NotificationChannelSetting, locale Locale, now time.Time,
+) error {
+	deliveries := make(map[*NotificationDelivery]NotificationChannelSetting, len(destinations))
+	for _, destination := range destinations {
+		delivery, err := NewNotificationDelivery(nil, notification.ID, now, destination.Kind)
+		if err != nil {
+			return err
+		}
+		claimed, err := d.deliveryRepository.Claim(ctx, delivery)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			logger.Info("notification already delivered", "notification_id", notification.ID,
+				"channel", destination.Kind, "slot", delivery.ScheduledAt)
+			continue
+		}
+		deliveries[delivery] = destination
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	summary, err := d.summaryGenerator.Generate(ctx, notification.Symbols(), notification.Window, locale, now)
+	var errs []error
+	for delivery, destination := range deliveries {
+		e := err
+		if e == nil {
+			e = d.enqueue(ctx, delivery, destination, summary)
+		}
+		if e == nil {
+			logger.Info("notification queued", "notification_id", notification.ID,
+				"member_id", notification.MemberID, "channel", destination.Kind)
+			continue
+		}
+		// a failed delivery is claimed again by a rerun in the same hour
+		delivery.Failed(e)
+		if ue := d.deliveryRepository.Update(ctx, delivery); ue != nil {
+			e = errors.Join(e, ue)
+		}
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
+func (d *NotificationDispatcher) enqueue(
+	ctx context.Context, delivery *NotificationDelivery,
+	destination NotificationChannelSetting, summary *MarketSummary,
+) error {
+	message, err := NewOutboxMessage(
+		nil, &delivery.ID, destination.Kind, Cfg.FROM, destination.Destination,
+		summary.Subject, summary.Text,
+	)
+	if err != nil {
+		return err
+	}
+	if destination.Kind == DeliveryChannelEmail {
+		message.HTML = summary.HTML
+		message.Inline = summary.Images
+	}
+	return d.outboxRepository.Save(ctx, []*OutboxMessage{message})
+}
+
+// Run dispatches due notifications every interval until ctx is canceled.
+func (d *NotificationDispatcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Dispatch(ctx, time.Now()); err != nil {
+			logger.Error("failed to dispatch notifications", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,239 @@
+package notifystock_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/heyjun3/notify-stock/internal/calendar"
+)
+
+// serverClient sends every request to the server, whatever its host.
+type serverClient struct {
+	server *httptest.Server
+}
+
+func (c *serverClient) Do(req *http.Request) (*http.Response, error) {
+	URL, err := url.Parse(c.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme, req.URL.Host = URL.Scheme, URL.Host
+	return c.server.Client().Do(req)
+}
+
+type sentMail struct {
+	from, to, subject, text, html string
+	inline                        []notify.InlineImage
+}
+
+type fakeMailService struct {
+	sent []sentMail
+	err  error
+}
+
+func (f *fakeMailService) Send(mail notify.Mail) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.sent = append(f.sent, sentMail{
+		from: mail.From, to: mail.To, subject: mail.Subject, text: mail.Text, html: mail.HTML,
+		inline: mail.Inline,
+	})
+	return fmt.Sprintf("message-%d", len(f.sent)), nil
+}
+
+func emailChannels(mail notify.MailService) notify.Channels {
+	return notify.Channels{notify.DeliveryChannelEmail: notify.NewEmailChannel(mail)}
+}
+
+func TestNotificationDispatcher(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	now := time.Now().UTC()
+
+	memberRepository := notify.NewMemberRepository(db)
+	symbolRepository := notify.NewSymbolRepository(db)
+	stockRepository := notify.NewStockRepository(db)
+	notificationRepository := notify.NewNotificationRepository(db)
+
+	symbol := notify.NewSymbolDetail("N225", "NIKKEI 225", "NIKKEI", "JPY", decimal.NewFromInt(1000), decimal.NewFromInt(900))
+	if err := symbolRepository.Save(ctx, []notify.SymbolDetail{*symbol}); err != nil {
+		panic(err)
+	}
+	if err := stockRepository.Save(ctx, []notify.Stock{
+		{Symbol: "N225", Timestamp: now.AddDate(0, 0, -2), Open: 900, Close: 900, High: 900, Low: 900},
+		{Symbol: "N225", Timestamp: now.AddDate(0, 0, -1), Open: 1000, Close: 1000, High: 1000, Low: 1000},
+	}); err != nil {
+		panic(err)
+	}
+
+	deliveryRepository := notify.NewNotificationDeliveryRepository(db)
+	outboxRepository := notify.NewOutboxRepository(db)
+	dispatcher := notify.NewNotificationDispatcher(
+		notify.NewMarketSummaryGenerator(stockRepository, symbolRepository),
+		outboxRepository,
+		notificationRepository,
+		memberRepository,
+		deliveryRepository,
+		symbolRepository,
+	)
+	newWorker := func(mail notify.MailService) *notify.OutboxWorker {
+		return notify.NewOutboxWorker(emailChannels(mail), outboxRepository, deliveryRepository, notify.OutboxWorkerOption{
+			MaxAttempts: 3,
+			Backoff:     notify.Backoff{Base: time.Minute, Max: time.Hour},
+			BatchSize:   10,
+			Lease:       time.Minute,
+		})
+	}
+	createNotification := func(t *testing.T, email string) *notify.Notification {
+		member, err := notify.NewGoogleMember(nil, email, email, true, "Name", "Given", "Family", "Picture")
+		assert.NoError(t, err)
+		err = memberRepository.Save(ctx, []*notify.Member{member})
+		assert.NoError(t, err)
+		notification, err := notify.NewNotification(nil, member.ID, []string{"N225"}, now.Truncate(time.Hour))
+		assert.NoError(t, err)
+		err = notificationRepository.Save(ctx, []notify.Notification{*notification})
+		assert.NoError(t, err)
+		return notification
+	}
+
+	t.Run("deliver once per hour", func(t *testing.T) {
+		createNotification(t, "dispatch@example.com")
+		mail := &fakeMailService{}
+
+		err := dispatcher.Dispatch(ctx, now)
+		assert.NoError(t, err)
+		err = dispatcher.Dispatch(ctx, now)
+		assert.NoError(t, err)
+		_, err = newWorker(mail).Drain(ctx, now)
+		assert.NoError(t, err)
+
+		count := 0
+		for _, m := range mail.sent {
+			if m.to == "dispatch@example.com" {
+				count++
+				assert.Contains(t, m.text, "NIKKEI 225")
+				assert.Contains(t, m.html, `<img src="cid:sparkline-0.png"`)
+				assert.Len(t, m.inline, 1)
+			}
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("retry after failed send", func(t *testing.T) {
+		notification := createNotification(t, "retry@example.com")
+		failing := &fakeMailService{err: fmt.Errorf("mail server down")}
+
+		err := dispatcher.Dispatch(ctx, now)
+		assert.NoError(t, err)
+		_, err = newWorker(failing).Drain(ctx, now)
+		assert.NoError(t, err)
+
+		deliveries, err := deliveryRepository.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deliveries))
+		assert.Equal(t, notify.DeliveryStatusPending, deliveries[0].Status)
+		assert.Equal(t, "mail server down", deliveries[0].Error)
+
+		mail := &fakeMailService{}
+		_, err = newWorker(mail).Drain(ctx, now.Add(time.Minute))
+		assert.NoError(t, err)
+
+		recipients := make([]string, 0, len(mail.sent))
+		for _, m := range mail.sent {
+			recipients = append(recipients, m.to)
+		}
+		assert.Contains(t, recipients, "retry@example.com")
+
+		deliveries, err = deliveryRepository.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deliveries))
+		assert.Equal(t, notify.DeliveryStatusSent, deliveries[0].Status)
+		assert.Equal(t, notify.DeliveryChannelEmail, deliveries[0].Channel)
+		assert.Equal(t, 2, deliveries[0].Attempts)
+		assert.NotEmpty(t, deliveries[0].Response)
+		assert.Empty(t, deliveries[0].Error)
+	})
+
+	t.Run("skip a holiday of the exchange", func(t *testing.T) {
+		jst := time.FixedZone("JST", 9*60*60)
+		// Coming of Age Day, after a weekend in Tokyo
+		holiday := time.Date(2024, 1, 8, 20, 0, 0, 0, jst)
+		n225 := notify.NewSymbolDetail("^N225", "Nikkei 225", "Nikkei 225", "JPY",
+			decimal.NewFromInt(33377), decimal.NewFromInt(33288), notify.WithExchange(calendar.TSE))
+		assert.NoError(t, symbolRepository.Save(ctx, []notify.SymbolDetail{*n225}))
+		assert.NoError(t, stockRepository.Save(ctx, []notify.Stock{
+			{Symbol: "^N225", Timestamp: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Open: 33288, Close: 33288, High: 33288, Low: 33288},
+			{Symbol: "^N225", Timestamp: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), Open: 33763, Close: 33763, High: 33763, Low: 33763},
+		}))
+		member, err := notify.NewGoogleMember(nil, "holiday@example.com", "holiday@example.com", true,
+			"Name", "Given", "Family", "Picture")
+		assert.NoError(t, err)
+		assert.NoError(t, memberRepository.Save(ctx, []*notify.Member{member}))
+		notification, err := notify.NewNotification(nil, member.ID, []string{"^N225"}, holiday)
+		assert.NoError(t, err)
+		assert.NoError(t, notificationRepository.Save(ctx, []notify.Notification{*notification}))
+
+		err = dispatcher.Dispatch(ctx, holiday)
+		assert.NoError(t, err)
+
+		deliveries, err := deliveryRepository.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		// the session of the next day is summarized
+		err = dispatcher.Dispatch(ctx, holiday.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+
+		deliveries, err = deliveryRepository.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+	})
+
+	t.Run("fan out to every channel", func(t *testing.T) {
+		var received []string
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received = append(received, string(body))
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		notification := createNotification(t, "fanout@example.com")
+		err := notification.SetChannels([]notify.NotificationChannelSetting{
+			{Kind: notify.DeliveryChannelEmail},
+			{Kind: notify.DeliveryChannelSlack, Destination: "https://hooks.slack.com/services/T000/B000/XXX"},
+		})
+		assert.NoError(t, err)
+		err = notificationRepository.Save(ctx, []notify.Notification{*notification})
+		assert.NoError(t, err)
+
+		err = dispatcher.Dispatch(ctx, now)
+		assert.NoError(t, err)
+		mail := &fakeMailService{}
+		channels := emailChannels(mail)
+		channels[notify.DeliveryChannelSlack] = notify.NewSlackChannel(&serverClient{server: server})
+		worker := notify.NewOutboxWorker(channels, outboxRepository, deliveryRepository, notify.DefaultOutboxWorkerOption())
+		_, err = worker.Drain(ctx, now)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, len(received))
+		assert.Contains(t, received[0], "NIKKEI 225")
+		deliveries, err := deliveryRepository.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(deliveries))
+		for _, delivery := range deliveries {
+			assert.Equal(t, notify.DeliveryStatusSent, delivery.Status)
+		}
+	})
+}
@@ -0,0 +1,276 @@
+package notifystock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type BackfillStatus string
+
+const (
+	BackfillStatusPending BackfillStatus = "pending"
+	BackfillStatusFailed  BackfillStatus = "failed"
+	BackfillStatusDone    BackfillStatus = "done"
+)
+
+// BackfillJob registers the prices of a symbol over a long period, one chunk
+// at a time. Cursor is the start of the next chunk, so that an interrupted
+// or failed job resumes where it stopped.
+type BackfillJob struct {
+	bun.BaseModel `bun:"table:backfill_jobs"`
+
+	ID     uuid.UUID      `bun:"id,type:uuid,pk"`
+	Symbol string         `bun:"symbol,type:text,notnull"`
+	Start  time.Time      `bun:"start_date,type:timestamp,notnull"`
+	End    time.Time      `bun:"end_date,type:timestamp,notnull"`
+	Cursor time.Time      `bun:"cursor_date,type:timestamp,notnull"`
+	Status BackfillStatus `bun:"status,type:text,notnull"`
+	// Chunks is the number of chunks registered so far.
+	Chunks    int       `bun:"chunks,notnull"`
+	Error     string    `bun:"error,type:text,nullzero"`
+	CreatedAt time.Time `bun:"created_at,type:timestamp,notnull"`
+	UpdatedAt time.Time `bun:"updated_at,type:timestamp,notnull"`
+}
+
+func NewBackfillJob(symbol string, start, end, now time.Time) (*BackfillJob, error) {
+	if !start.Before(end) {
+		return nil, NewValidationError("Invalid period",
+			fmt.Sprintf("start %s is not before end %s", start.Format(time.DateOnly), end.Format(time.DateOnly)))
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	return &BackfillJob{
+		ID:        id,
+		Symbol:    symbol,
+		Start:     start,
+		End:       end,
+		Cursor:    start,
+		Status:    BackfillStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (j *BackfillJob) Done() bool {
+	return j.Status == BackfillStatusDone
+}
+
+// NextChunk returns the period of the next chunk, at most years long.
+func (j *BackfillJob) NextChunk(years int) (time.Time, time.Time) {
+	end := j.Cursor.AddDate(max(years, 1), 0, 0)
+	if end.After(j.End) {
+		end = j.End
+	}
+	return j.Cursor, end
+}
+
+// Advance records that the prices until to are registered.
+func (j *BackfillJob) Advance(to, now time.Time) {
+	j.Cursor = to
+	j.Chunks++
+	j.Status = BackfillStatusPending
+	if !j.Cursor.Before(j.End) {
+		j.Status = BackfillStatusDone
+	}
+	j.Error = ""
+	j.UpdatedAt = now
+}
+
+// Extend moves the end of an unfinished job to end when it is later.
+func (j *BackfillJob) Extend(end, now time.Time) {
+	if j.Done() || !end.After(j.End) {
+		return
+	}
+	j.End = end
+	j.UpdatedAt = now
+}
+
+func (j *BackfillJob) Fail(err error, now time.Time) {
+	j.Status = BackfillStatusFailed
+	j.Error = err.Error()
+	j.UpdatedAt = now
+}
+
+type BackfillJobRepository struct {
+	db *bun.DB
+}
+
+func NewBackfillJobRepository(db *bun.DB) *BackfillJobRepository {
+	return &BackfillJobRepository{
+		db: db,
+	}
+}
+
+func (r *BackfillJobRepository) Save(ctx context.Context, job *BackfillJob) error {
+	_, err := r.db.NewInsert().
+		Model(job).
+		On("CONFLICT (id) DO UPDATE").
+		Set(strings.Join([]string{
+			"end_date = EXCLUDED.end_date",
+			"cursor_date = EXCLUDED.cursor_date",
+			"status = EXCLUDED.status",
+			"chunks = EXCLUDED.chunks",
+			"error = EXCLUDED.error",
+			"updated_at = EXCLUDED.updated_at",
+		}, ",")).
+		Exec(ctx)
+	return err
+}
+
+// GetUnfinished returns the latest job of the symbol from start that is not
+// done, or sql.ErrNoRows.
+func (r *BackfillJobRepository) GetUnfinished(
+	ctx context.Context, symbol string, start time.Time) (*BackfillJob, error) {
+	var job BackfillJob
+	if err := r.db.NewSelect().
+		Model(&job).
+		Where("symbol = ?", symbol).
+		Where("start_date = ?", start).
+		Where("status <> ?", BackfillStatusDone).
+		Order("created_at DESC").
+		Limit(1).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetBySymbol returns the jobs of the symbol, the latest first.
+func (r *BackfillJobRepository) GetBySymbol(ctx context.Context, symbol string) ([]*BackfillJob, error) {
+	var jobs []*BackfillJob
+	if err := r.db.NewSelect().
+		Model(&jobs).
+		Where("symbol = ?", symbol).
+		Order("created_at DESC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+type BackfillOption struct {
+	// ChunkYears is the period fetched at a time.
+	ChunkYears int
+	// DefaultYears is how far back a symbol whose first trade date is not
+	// known is backfilled without a start.
+	DefaultYears int
+}
+
+func DefaultBackfillOption() BackfillOption {
+	return BackfillOption{
+		ChunkYears:   5,
+		DefaultYears: 5,
+	}
+}
+
+// Backfiller registers the history of symbols in chunks, recording the
+// progress of each symbol in a BackfillJob.
+type Backfiller struct {
+	register              *StockRegister
+	symbolRepository      *SymbolRepository
+	backfillJobRepository *BackfillJobRepository
+	option                BackfillOption
+}
+
+func NewBackfiller(
+	register *StockRegister,
+	symbolRepository *SymbolRepository,
+	backfillJobRepository *BackfillJobRepository,
+	option BackfillOption,
+) *Backfiller {
+	return &Backfiller{
+		register:              register,
+		symbolRepository:      symbolRepository,
+		backfillJobRepository: backfillJobRepository,
+		option:                option,
+	}
+}
+
+// Backfill registers the prices of the symbols from start to end, resuming
+// the unfinished job of each symbol from the same start. Without start, a
+// symbol is backfilled since its first trade date. A start before the first
+// trade date is moved to it, since there is nothing to fetch before.
+//
+// Chunks are registered from the oldest, so the symbol detail is the latest
+// once a job is done. The returned error joins a SymbolError for every
+// symbol that failed; the others are still backfilled.
+func (b *Backfiller) Backfill(ctx context.Context, symbols []string, start, end time.Time) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+	details, err := b.symbolRepository.GetBySymbols(ctx, symbols)
+	if err != nil {
+		return err
+	}
+	firstTradeDates := make(map[string]time.Time, len(details))
+	for _, detail := range details {
+		if detail.FirstTradeDate.Valid {
+			firstTradeDates[detail.Symbol] = detail.FirstTradeDate.Time
+		}
+	}
+	var errs []error
+	for _, symbol := range symbols {
+		from := start
+		if first, ok := firstTradeDates[symbol]; ok && (from.IsZero() || from.Before(first)) {
+			from = first
+		}
+		if from.IsZero() {
+			from = end.AddDate(-b.option.DefaultYears, 0, 0)
+		}
+		if err := b.backfill(ctx, symbol, from, end); err != nil {
+			errs = append(errs, &SymbolError{Symbol: symbol, Err: err})
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *Backfiller) backfill(ctx context.Context, symbol string, start, end time.Time) error {
+	job, err := b.backfillJobRepository.GetUnfinished(ctx, symbol, start)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		job, err = NewBackfillJob(symbol, start, end, time.Now())
+	case err == nil:
+		job.Extend(end, time.Now())
+		logger.Info("resuming backfill", "symbol", symbol, "cursor", job.Cursor, "chunks", job.Chunks)
+	}
+	if err != nil {
+		return err
+	}
+	if err := b.backfillJobRepository.Save(ctx, job); err != nil {
+		return err
+	}
+	for !job.Done() {
+		from, to := job.NextChunk(b.option.ChunkYears)
+		register := b.register.RegisterHistoryBySymbol
+		// only the chunk ending now holds the latest price of the symbol
+		if to.After(time.Now().AddDate(0, 0, -1)) {
+			register = b.register.RegisterStockBySymbol
+		}
+		if err := register(ctx, symbol, from, to); err != nil {
+			job.Fail(err, time.Now())
+			// the progress is kept even when ctx is canceled
+			if saveErr := b.backfillJobRepository.Save(context.WithoutCancel(ctx), job); saveErr != nil {
+				return errors.Join(err, saveErr)
+			}
+			return err
+		}
+		job.Advance(to, time.Now())
+		if err := b.backfillJobRepository.Save(ctx, job); err != nil {
+			return err
+		}
+		logger.Info("backfilled chunk", "symbol", symbol, "start", from, "end", to)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package notifystock_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestBackfillJob(t *testing.T) {
+	start := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	job, err := notify.NewBackfillJob("^N225", start, end, now)
+	assert.NoError(t, err)
+
+	var chunks [][2]time.Time
+	for !job.Done() {
+		from, to := job.NextChunk(5)
+		chunks = append(chunks, [2]time.Time{from, to})
+		job.Advance(to, now)
+	}
+
+	assert.Equal(t, [][2]time.Time{
+		{start, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), end},
+	}, chunks)
+	assert.Equal(t, 3, job.Chunks)
+
+	_, err = notify.NewBackfillJob("^N225", end, start, now)
+	assert.Error(t, err)
+}
+
+// limitedClient fails every request after the first limit ones.
+type limitedClient struct {
+	client notify.HTTPClientInterface
+	limit  int
+}
+
+func (c *limitedClient) Do(req *http.Request) (*http.Response, error) {
+	if c.limit <= 0 {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewBufferString("not found")),
+		}, nil
+	}
+	c.limit--
+	return c.client.Do(req)
+}
+
+func TestBackfiller(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	start := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+	newBackfiller := func(client notify.HTTPClientInterface) *notify.Backfiller {
+		return notify.NewBackfiller(
+			notify.NewStockRegister(
+				notify.NewMarketDataProvider(client),
+				notify.NewStockRepository(db),
+				notify.NewSymbolRepository(db),
+				notify.NewCorporateActionRepository(db),
+				notify.NewTrackedSymbolRepository(db),
+				notify.DefaultStockRegisterOption(),
+			),
+			notify.NewSymbolRepository(db),
+			notify.NewBackfillJobRepository(db),
+			notify.DefaultBackfillOption(),
+		)
+	}
+	repository := notify.NewBackfillJobRepository(db)
+
+	// only the first chunk is fetched before the provider fails
+	interrupted := &fixtureClient{fixtures: map[string]string{yahooHost: "testdata/yahoo/chart_N225.json"}}
+	err := newBackfiller(&limitedClient{client: interrupted, limit: 1}).
+		Backfill(ctx, []string{"^N225"}, start, end)
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"^N225"}, notify.FailedSymbols(err))
+	jobs, err := repository.GetBySymbol(ctx, "^N225")
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, notify.BackfillStatusFailed, jobs[0].Status)
+	assert.Equal(t, 1, jobs[0].Chunks)
+	assert.True(t, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC).Equal(jobs[0].Cursor))
+
+	resumed := &fixtureClient{fixtures: map[string]string{yahooHost: "testdata/yahoo/chart_N225.json"}}
+	err = newBackfiller(resumed).Backfill(ctx, []string{"^N225"}, start, end)
+
+	assert.NoError(t, err)
+	assert.Len(t, resumed.requests, 2)
+	assert.Equal(t, "1546300800", resumed.requests[0].URL.Query().Get("period1"))
+	jobs, err = repository.GetBySymbol(ctx, "^N225")
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, notify.BackfillStatusDone, jobs[0].Status)
+	assert.Equal(t, 3, jobs[0].Chunks)
+	assert.Empty(t, jobs[0].Error)
+
+	// the chunks end in the past, so the symbol detail is left alone
+	_, err = notify.NewSymbolRepository(db).Get(ctx, "^N225")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
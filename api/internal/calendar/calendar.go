@@ -0,0 +1,329 @@
+// Package calendar tells the trading days and hours of the exchanges the
+// supported symbols are listed on.
+package calendar
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"time"
+	// the exchange time zones must not depend on the zoneinfo of the host
+	_ "time/tzdata"
+
+	yaml "github.com/goccy/go-yaml"
+)
+
+type Exchange string
+
+const (
+	TSE    Exchange = "TSE"
+	NYSE   Exchange = "NYSE"
+	NASDAQ Exchange = "NASDAQ"
+)
+
+func (e Exchange) IsValid() bool {
+	_, ok := calendars[e]
+	return ok
+}
+
+// yahooExchanges maps the exchange names of the Yahoo chart API, including
+// the ones of the indices computed from the listings of an exchange.
+var yahooExchanges = map[string]Exchange{
+	"JPX": TSE,
+	"OSA": TSE,
+	"NYQ": NYSE,
+	"ASE": NYSE,
+	"PCX": NYSE,
+	"DJI": NYSE,
+	"SNP": NYSE,
+	"NMS": NASDAQ,
+	"NGM": NASDAQ,
+	"NCM": NASDAQ,
+	"NIM": NASDAQ,
+}
+
+var timezoneExchanges = map[string]Exchange{
+	"Asia/Tokyo":       TSE,
+	"America/New_York": NYSE,
+}
+
+// ExchangeFromYahoo returns the exchange of the Yahoo exchange name, falling
+// back to the main exchange of its time zone.
+func ExchangeFromYahoo(exchangeName, timezone string) (Exchange, bool) {
+	if exchange, ok := yahooExchanges[exchangeName]; ok {
+		return exchange, true
+	}
+	exchange, ok := timezoneExchanges[timezone]
+	return exchange, ok
+}
+
+//go:embed data/*.yaml
+var data embed.FS
+
+var calendars = map[Exchange]*Calendar{
+	TSE:  mustLoad(TSE, "data/tse.yaml"),
+	NYSE: mustLoad(NYSE, "data/nyse.yaml"),
+	// NASDAQ closes on the same days as NYSE
+	NASDAQ: mustLoad(NASDAQ, "data/nyse.yaml"),
+}
+
+// For returns the calendar of the exchange.
+func For(exchange Exchange) (*Calendar, error) {
+	calendar, ok := calendars[exchange]
+	if !ok {
+		return nil, fmt.Errorf("unsupported exchange: %q", exchange)
+	}
+	return calendar, nil
+}
+
+type session struct {
+	since time.Time
+	open  time.Duration
+	close time.Duration
+}
+
+// Calendar knows the holidays, half-days and session hours of an exchange.
+// Days outside of the embedded holidays are trading days when they are
+// weekdays, including the days outside of the covered period whose holidays
+// are not listed.
+//
+// A day is given as any time on it: its year, month and day are read in its
+// own location. The days returned are midnight in UTC, like the timestamps
+// of the stored daily prices.
+type Calendar struct {
+	exchange Exchange
+	location *time.Location
+	sessions []session
+	holidays map[time.Time]string
+	halfDays map[time.Time]time.Duration
+	// from and to are the first and the last day of the covered period.
+	from time.Time
+	to   time.Time
+}
+
+type calendarFile struct {
+	Timezone string `yaml:"timezone"`
+	Sessions []struct {
+		Since string `yaml:"since"`
+		Open  string `yaml:"open"`
+		Close string `yaml:"close"`
+	} `yaml:"sessions"`
+	Covers struct {
+		From string `yaml:"from"`
+		To   string `yaml:"to"`
+	} `yaml:"covers"`
+	Holidays []struct {
+		Date string `yaml:"date"`
+		Name string `yaml:"name"`
+	} `yaml:"holidays"`
+	HalfDays []struct {
+		Date  string `yaml:"date"`
+		Close string `yaml:"close"`
+	} `yaml:"half_days"`
+}
+
+func mustLoad(exchange Exchange, path string) *Calendar {
+	calendar, err := load(exchange, path)
+	if err != nil {
+		panic(fmt.Errorf("failed to load the calendar of %s: %w", exchange, err))
+	}
+	return calendar
+}
+
+func load(exchange Exchange, path string) (*Calendar, error) {
+	buf, err := data.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file calendarFile
+	if err := yaml.Unmarshal(buf, &file); err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(file.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	calendar := &Calendar{
+		exchange: exchange,
+		location: location,
+		holidays: make(map[time.Time]string, len(file.Holidays)),
+		halfDays: make(map[time.Time]time.Duration, len(file.HalfDays)),
+	}
+	for _, s := range file.Sessions {
+		since, err := time.Parse(time.DateOnly, s.Since)
+		if err != nil {
+			return nil, err
+		}
+		open, err := parseClock(s.Open)
+		if err != nil {
+			return nil, err
+		}
+		close, err := parseClock(s.Close)
+		if err != nil {
+			return nil, err
+		}
+		calendar.sessions = append(calendar.sessions, session{since: since, open: open, close: close})
+	}
+	if len(calendar.sessions) == 0 {
+		return nil, fmt.Errorf("no sessions")
+	}
+	slices.SortFunc(calendar.sessions, func(a, b session) int {
+		return a.since.Compare(b.since)
+	})
+	if calendar.from, err = time.Parse(time.DateOnly, file.Covers.From); err != nil {
+		return nil, fmt.Errorf("covered period: %w", err)
+	}
+	if calendar.to, err = time.Parse(time.DateOnly, file.Covers.To); err != nil {
+		return nil, fmt.Errorf("covered period: %w", err)
+	}
+	for _, h := range file.Holidays {
+		date, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, err
+		}
+		calendar.holidays[date] = h.Name
+	}
+	for _, h := range file.HalfDays {
+		date, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, err
+		}
+		close, err := parseClock(h.Close)
+		if err != nil {
+			return nil, err
+		}
+		calendar.halfDays[date] = close
+	}
+	return calendar, nil
+}
+
+// parseClock returns the time of day of "15:04" as the duration since
+// midnight.
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// day returns the date of t as midnight in UTC.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (c *Calendar) Exchange() Exchange {
+	return c.exchange
+}
+
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// Date returns the day at the exchange at the instant t.
+func (c *Calendar) Date(t time.Time) time.Time {
+	return day(t.In(c.location))
+}
+
+// Holiday returns the name of the holiday the exchange is closed for on the
+// day.
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	name, ok := c.holidays[day(date)]
+	return name, ok
+}
+
+// Covers reports whether the holidays of the day are listed.
+func (c *Calendar) Covers(date time.Time) bool {
+	d := day(date)
+	return !d.Before(c.from) && !d.After(c.to)
+}
+
+// Coverage returns the first and the last day of the period the holidays are
+// listed for.
+func (c *Calendar) Coverage() (from, to time.Time) {
+	return c.from, c.to
+}
+
+func (c *Calendar) IsTradingDay(date time.Time) bool {
+	weekday := date.Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(date)
+	return !holiday
+}
+
+// IsHalfDay reports whether the exchange closes early on the day.
+func (c *Calendar) IsHalfDay(date time.Time) bool {
+	_, ok := c.halfDays[day(date)]
+	return ok && c.IsTradingDay(date)
+}
+
+// Session returns when the exchange opens and closes on the day, or false
+// when the day is not a trading day.
+func (c *Calendar) Session(date time.Time) (open, close time.Time, ok bool) {
+	if !c.IsTradingDay(date) {
+		return time.Time{}, time.Time{}, false
+	}
+	d := day(date)
+	hours := c.sessions[0]
+	for _, s := range c.sessions[1:] {
+		if !d.Before(s.since) {
+			hours = s
+		}
+	}
+	closeAt := hours.close
+	if halfDay, ok := c.halfDays[d]; ok {
+		closeAt = halfDay
+	}
+	midnight := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, c.location)
+	return midnight.Add(hours.open), midnight.Add(closeAt), true
+}
+
+// IsOpen reports whether the exchange is in session at the instant t.
+func (c *Calendar) IsOpen(t time.Time) bool {
+	open, close, ok := c.Session(c.Date(t))
+	return ok && !t.Before(open) && t.Before(close)
+}
+
+// PreviousTradingDay returns the last trading day before the day.
+func (c *Calendar) PreviousTradingDay(date time.Time) time.Time {
+	d := day(date).AddDate(0, 0, -1)
+	for !c.IsTradingDay(d) {
+		d = d.AddDate(0, 0, -1)
+	}
+	return d
+}
+
+// NextTradingDay returns the first trading day after the day.
+func (c *Calendar) NextTradingDay(date time.Time) time.Time {
+	d := day(date).AddDate(0, 0, 1)
+	for !c.IsTradingDay(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// AddTradingDays returns the day n trading days after the day, or before it
+// when n is negative.
+func (c *Calendar) AddTradingDays(date time.Time, n int) time.Time {
+	d := day(date)
+	for ; n > 0; n-- {
+		d = c.NextTradingDay(d)
+	}
+	for ; n < 0; n++ {
+		d = c.PreviousTradingDay(d)
+	}
+	return d
+}
+
+// LastClose returns the close of the latest session that ended at or before
+// the instant t.
+func (c *Calendar) LastClose(t time.Time) time.Time {
+	d := c.Date(t)
+	if _, close, ok := c.Session(d); ok && !close.After(t) {
+		return close
+	}
+	_, close, _ := c.Session(c.PreviousTradingDay(d))
+	return close
+}
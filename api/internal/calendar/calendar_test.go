@@ -0,0 +1,159 @@
+package calendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/heyjun3/notify-stock/internal/calendar"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestIsTradingDay(t *testing.T) {
+	tse, err := calendar.For(calendar.TSE)
+	assert.NoError(t, err)
+	nyse, err := calendar.For(calendar.NYSE)
+	assert.NoError(t, err)
+	nasdaq, err := calendar.For(calendar.NASDAQ)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		calendar *calendar.Calendar
+		date     time.Time
+		want     bool
+	}{
+		{"tse weekday", tse, date(2024, 1, 9), true},
+		{"tse weekend", tse, date(2024, 1, 6), false},
+		{"tse coming of age day", tse, date(2024, 1, 8), false},
+		{"tse new year holiday", tse, date(2025, 1, 3), false},
+		{"tse citizens' holiday", tse, date(2026, 9, 22), false},
+		{"nyse weekday", nyse, date(2024, 1, 8), true},
+		{"nyse good friday", nyse, date(2024, 3, 29), false},
+		{"nyse observed independence day", nyse, date(2026, 7, 3), false},
+		{"nasdaq follows nyse", nasdaq, date(2025, 11, 27), false},
+		{"nyse half-day is a trading day", nyse, date(2025, 12, 24), true},
+		{"tse system failure", tse, date(2020, 10, 1), false},
+		{"nyse observed christmas day", nyse, date(2021, 12, 24), false},
+		{"nyse new year's day on a saturday is not observed", nyse, date(2021, 12, 31), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.calendar.IsTradingDay(tt.date))
+		})
+	}
+}
+
+func TestCovers(t *testing.T) {
+	for _, exchange := range []calendar.Exchange{calendar.TSE, calendar.NYSE, calendar.NASDAQ} {
+		c, err := calendar.For(exchange)
+		assert.NoError(t, err)
+
+		from, to := c.Coverage()
+
+		assert.Equal(t, date(2020, 1, 1), from)
+		assert.Equal(t, date(2027, 12, 31), to)
+		assert.True(t, c.Covers(date(2020, 1, 1)))
+		assert.True(t, c.Covers(time.Date(2027, 12, 31, 23, 0, 0, 0, time.UTC)))
+		assert.False(t, c.Covers(date(2019, 12, 31)))
+		assert.False(t, c.Covers(date(2028, 1, 1)))
+	}
+}
+
+func TestHoliday(t *testing.T) {
+	tse, _ := calendar.For(calendar.TSE)
+
+	name, ok := tse.Holiday(date(2024, 1, 8))
+
+	assert.True(t, ok)
+	assert.Equal(t, "Coming of Age Day", name)
+}
+
+func TestSession(t *testing.T) {
+	tse, _ := calendar.For(calendar.TSE)
+	nyse, _ := calendar.For(calendar.NYSE)
+
+	t.Run("regular", func(t *testing.T) {
+		open, close, ok := nyse.Session(date(2025, 7, 2))
+
+		assert.True(t, ok)
+		assert.True(t, time.Date(2025, 7, 2, 13, 30, 0, 0, time.UTC).Equal(open))
+		assert.True(t, time.Date(2025, 7, 2, 20, 0, 0, 0, time.UTC).Equal(close))
+		assert.False(t, nyse.IsHalfDay(date(2025, 7, 2)))
+	})
+	t.Run("half-day", func(t *testing.T) {
+		_, close, ok := nyse.Session(date(2025, 7, 3))
+
+		assert.True(t, ok)
+		assert.True(t, time.Date(2025, 7, 3, 17, 0, 0, 0, time.UTC).Equal(close))
+		assert.True(t, nyse.IsHalfDay(date(2025, 7, 3)))
+	})
+	t.Run("extended hours", func(t *testing.T) {
+		_, before, _ := tse.Session(date(2024, 11, 1))
+		_, after, _ := tse.Session(date(2024, 11, 5))
+
+		assert.True(t, time.Date(2024, 11, 1, 6, 0, 0, 0, time.UTC).Equal(before))
+		assert.True(t, time.Date(2024, 11, 5, 6, 30, 0, 0, time.UTC).Equal(after))
+	})
+	t.Run("holiday", func(t *testing.T) {
+		_, _, ok := tse.Session(date(2024, 1, 8))
+
+		assert.False(t, ok)
+	})
+}
+
+func TestIsOpen(t *testing.T) {
+	tse, _ := calendar.For(calendar.TSE)
+
+	assert.True(t, tse.IsOpen(time.Date(2024, 1, 9, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, tse.IsOpen(time.Date(2024, 1, 9, 7, 0, 0, 0, time.UTC)))
+	assert.False(t, tse.IsOpen(time.Date(2024, 1, 8, 1, 0, 0, 0, time.UTC)))
+}
+
+func TestTradingDays(t *testing.T) {
+	tse, _ := calendar.For(calendar.TSE)
+
+	// the 6th and 7th are a weekend and the 8th is Coming of Age Day
+	assert.Equal(t, date(2024, 1, 5), tse.PreviousTradingDay(date(2024, 1, 9)))
+	assert.Equal(t, date(2024, 1, 9), tse.NextTradingDay(date(2024, 1, 5)))
+	assert.Equal(t, date(2023, 12, 29), tse.AddTradingDays(date(2024, 1, 9), -3))
+	assert.Equal(t, date(2024, 1, 10), tse.AddTradingDays(date(2024, 1, 5), 2))
+}
+
+func TestLastClose(t *testing.T) {
+	nyse, _ := calendar.For(calendar.NYSE)
+
+	// Monday morning in Tokyo is still Sunday in New York
+	monday := time.Date(2024, 1, 8, 0, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	assert.True(t, time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC).Equal(nyse.LastClose(monday)))
+	// during the session the last close is the one of the day before
+	assert.True(t, time.Date(2024, 1, 8, 21, 0, 0, 0, time.UTC).Equal(
+		nyse.LastClose(time.Date(2024, 1, 9, 15, 0, 0, 0, time.UTC))))
+}
+
+func TestExchangeFromYahoo(t *testing.T) {
+	tests := []struct {
+		exchangeName string
+		timezone     string
+		want         calendar.Exchange
+		ok           bool
+	}{
+		{"OSA", "Asia/Tokyo", calendar.TSE, true},
+		{"NMS", "America/New_York", calendar.NASDAQ, true},
+		{"SNP", "America/New_York", calendar.NYSE, true},
+		{"XXX", "America/New_York", calendar.NYSE, true},
+		{"LSE", "Europe/London", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exchangeName, func(t *testing.T) {
+			exchange, ok := calendar.ExchangeFromYahoo(tt.exchangeName, tt.timezone)
+
+			assert.Equal(t, tt.want, exchange)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
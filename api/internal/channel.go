@@ -0,0 +1,259 @@
+package notifystock
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type ChannelMessage struct {
+	From    string
+	Subject string
+	Text    string
+	// HTML is only sent by channels that support it, with the images it
+	// refers to.
+	HTML   string
+	Inline []InlineImage
+}
+
+// Channel delivers a message to a destination such as an email address or a
+// webhook URL and returns the provider's response.
+type Channel interface {
+	Send(ctx context.Context, destination string, message ChannelMessage) (string, error)
+}
+
+type Channels map[DeliveryChannel]Channel
+
+type WebhookChannelOption struct {
+	Secret string
+}
+
+func NewChannels(
+	mailService MailService,
+	client HTTPClientInterface,
+	option WebhookChannelOption,
+) Channels {
+	return Channels{
+		DeliveryChannelEmail:   NewEmailChannel(mailService),
+		DeliveryChannelSlack:   NewSlackChannel(client),
+		DeliveryChannelDiscord: NewDiscordChannel(client),
+		DeliveryChannelWebhook: NewWebhookChannel(client, option),
+	}
+}
+
+type EmailChannel struct {
+	mailService MailService
+}
+
+var _ Channel = (*EmailChannel)(nil)
+
+func NewEmailChannel(mailService MailService) *EmailChannel {
+	return &EmailChannel{
+		mailService: mailService,
+	}
+}
+
+func (c *EmailChannel) Send(ctx context.Context, destination string, message ChannelMessage) (string, error) {
+	return c.mailService.Send(Mail{
+		From:    message.From,
+		To:      destination,
+		Subject: message.Subject,
+		Text:    message.Text,
+		HTML:    message.HTML,
+		Inline:  message.Inline,
+	})
+}
+
+type SlackChannel struct {
+	client HTTPClientInterface
+}
+
+var _ Channel = (*SlackChannel)(nil)
+
+func NewSlackChannel(client HTTPClientInterface) *SlackChannel {
+	return &SlackChannel{
+		client: client,
+	}
+}
+
+func (c *SlackChannel) Send(ctx context.Context, destination string, message ChannelMessage) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"text": "*" + message.Subject + "*\n" + message.Text,
+	})
+	if err != nil {
+		return "", err
+	}
+	return postJSON(ctx, c.client, destination, body, nil)
+}
+
+// discordContentLimit is the maximum length of a Discord message content.
+const discordContentLimit = 2000
+
+type DiscordChannel struct {
+	client HTTPClientInterface
+}
+
+var _ Channel = (*DiscordChannel)(nil)
+
+func NewDiscordChannel(client HTTPClientInterface) *DiscordChannel {
+	return &DiscordChannel{
+		client: client,
+	}
+}
+
+func (c *DiscordChannel) Send(ctx context.Context, destination string, message ChannelMessage) (string, error) {
+	content := []rune("**" + message.Subject + "**\n" + message.Text)
+	if len(content) > discordContentLimit {
+		content = content[:discordContentLimit]
+	}
+	body, err := json.Marshal(map[string]string{
+		"content": string(content),
+	})
+	if err != nil {
+		return "", err
+	}
+	return postJSON(ctx, c.client, destination, body, nil)
+}
+
+const (
+	WebhookSignatureHeader = "X-Notify-Stock-Signature"
+	WebhookTimestampHeader = "X-Notify-Stock-Timestamp"
+)
+
+// WebhookChannel posts the message as JSON. Every request is signed with
+// HMAC-SHA256 over "<timestamp>.<body>" so receivers can verify the sender.
+type WebhookChannel struct {
+	client HTTPClientInterface
+	secret string
+	now    func() time.Time
+}
+
+var _ Channel = (*WebhookChannel)(nil)
+
+func NewWebhookChannel(client HTTPClientInterface, option WebhookChannelOption) *WebhookChannel {
+	return &WebhookChannel{
+		client: client,
+		secret: option.Secret,
+		now:    time.Now,
+	}
+}
+
+type WebhookPayload struct {
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// ErrWebhookSecretRequired is returned for webhooks while no secret is
+// configured, since receivers could not verify unsigned requests.
+var ErrWebhookSecretRequired = errors.New("webhook secret is not configured")
+
+func (c *WebhookChannel) Send(ctx context.Context, destination string, message ChannelMessage) (string, error) {
+	if c.secret == "" {
+		return "", ErrWebhookSecretRequired
+	}
+	now := c.now()
+	body, err := json.Marshal(WebhookPayload{
+		Subject: message.Subject,
+		Text:    message.Text,
+		SentAt:  now.UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	return postJSON(ctx, c.client, destination, body, map[string]string{
+		WebhookTimestampHeader: timestamp,
+		WebhookSignatureHeader: "sha256=" + SignWebhook(c.secret, timestamp, body),
+	})
+}
+
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrPrivateAddress is returned when a destination resolves to an address
+// that is not reachable from the internet.
+var ErrPrivateAddress = errors.New("destination is a private address")
+
+// cgnat is the shared address space of carrier-grade NAT (RFC 6598).
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether ip is a global unicast address outside the
+// private, loopback, link-local and shared ranges.
+func IsPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
+
+// NewChannelHTTPClient returns the client the channels post with. It refuses
+// to connect to addresses that are not public, on every redirect as well, so
+// that a destination cannot reach the internal network even when its name
+// resolves to it.
+func NewChannelHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the destination
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+func postJSON(
+	ctx context.Context, client HTTPClientInterface,
+	URL string, body []byte, headers map[string]string,
+) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	response, err := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("webhook responded %s: %s", res.Status, strings.TrimSpace(string(response)))
+	}
+	if len(response) == 0 {
+		return res.Status, nil
+	}
+	return strings.TrimSpace(string(response)), nil
+}
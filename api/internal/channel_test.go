@@ -0,0 +1,150 @@
+package notifystock_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+type capturedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookServer(t *testing.T, status int, response string) (*httptest.Server, *[]capturedRequest) {
+	t.Helper()
+	requests := []capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		requests = append(requests, capturedRequest{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestSlackChannel(t *testing.T) {
+	server, requests := newWebhookServer(t, http.StatusOK, "ok")
+	channel := notify.NewSlackChannel(server.Client())
+
+	response, err := channel.Send(context.Background(), server.URL, notify.ChannelMessage{
+		Subject: "Market Summary", Text: "NIKKEI 225",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", response)
+	assert.Equal(t, 1, len(*requests))
+	var payload map[string]string
+	assert.NoError(t, json.Unmarshal((*requests)[0].body, &payload))
+	assert.Equal(t, "*Market Summary*\nNIKKEI 225", payload["text"])
+	assert.Equal(t, "application/json", (*requests)[0].header.Get("Content-Type"))
+}
+
+func TestDiscordChannel(t *testing.T) {
+	t.Run("send content", func(t *testing.T) {
+		server, requests := newWebhookServer(t, http.StatusNoContent, "")
+		channel := notify.NewDiscordChannel(server.Client())
+
+		response, err := channel.Send(context.Background(), server.URL, notify.ChannelMessage{
+			Subject: "Market Summary", Text: strings.Repeat("a", 3000),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "204 No Content", response)
+		var payload map[string]string
+		assert.NoError(t, json.Unmarshal((*requests)[0].body, &payload))
+		assert.True(t, strings.HasPrefix(payload["content"], "**Market Summary**\n"))
+		assert.Equal(t, 2000, len([]rune(payload["content"])))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server, _ := newWebhookServer(t, http.StatusTooManyRequests, "rate limited")
+		channel := notify.NewDiscordChannel(server.Client())
+
+		_, err := channel.Send(context.Background(), server.URL, notify.ChannelMessage{Subject: "s", Text: "t"})
+
+		assert.ErrorContains(t, err, "rate limited")
+	})
+}
+
+func TestWebhookChannel(t *testing.T) {
+	server, requests := newWebhookServer(t, http.StatusAccepted, "")
+	channel := notify.NewWebhookChannel(server.Client(), notify.WebhookChannelOption{Secret: "secret"})
+
+	_, err := channel.Send(context.Background(), server.URL, notify.ChannelMessage{
+		Subject: "Market Summary", Text: "NIKKEI 225",
+	})
+
+	assert.NoError(t, err)
+	request := (*requests)[0]
+	timestamp := request.header.Get(notify.WebhookTimestampHeader)
+	assert.NotEmpty(t, timestamp)
+	assert.Equal(t,
+		"sha256="+notify.SignWebhook("secret", timestamp, request.body),
+		request.header.Get(notify.WebhookSignatureHeader))
+	assert.NotEqual(t,
+		"sha256="+notify.SignWebhook("other", timestamp, request.body),
+		request.header.Get(notify.WebhookSignatureHeader))
+
+	var payload notify.WebhookPayload
+	assert.NoError(t, json.Unmarshal(request.body, &payload))
+	assert.Equal(t, "Market Summary", payload.Subject)
+	assert.Equal(t, "NIKKEI 225", payload.Text)
+}
+
+func TestWebhookChannelWithoutSecret(t *testing.T) {
+	server, requests := newWebhookServer(t, http.StatusAccepted, "")
+	channel := notify.NewWebhookChannel(server.Client(), notify.WebhookChannelOption{})
+
+	_, err := channel.Send(context.Background(), server.URL, notify.ChannelMessage{Subject: "s", Text: "t"})
+
+	assert.ErrorIs(t, err, notify.ErrWebhookSecretRequired)
+	assert.Empty(t, *requests)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.0.0.1", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, notify.IsPublicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestChannelHTTPClient(t *testing.T) {
+	server, requests := newWebhookServer(t, http.StatusOK, "ok")
+	channel := notify.NewSlackChannel(notify.NewChannelHTTPClient(time.Second))
+
+	_, err := channel.Send(context.Background(), server.URL, notify.ChannelMessage{Subject: "s", Text: "t"})
+
+	assert.ErrorIs(t, err, notify.ErrPrivateAddress)
+	assert.Empty(t, *requests)
+}
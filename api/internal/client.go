@@ -1,28 +1,41 @@
 package notifystock
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/heyjun3/notify-stock/internal/calendar"
 )
 
 type HTTPClientInterface interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:136.0) Gecko/20100101 Firefox/136.0"
+
+const YahooFinanceBaseURL = "https://query2.finance.yahoo.com"
+
+// FinanceClient fetches prices from the Yahoo Finance chart API.
 type FinanceClient struct {
-	Client HTTPClientInterface
+	Client  HTTPClientInterface
+	BaseURL string
+	Retry   RetryPolicy
 }
 
-func NewFinanceClient(client HTTPClientInterface) *FinanceClient {
+func NewFinanceClient(client HTTPClientInterface, baseURL string) *FinanceClient {
 	return &FinanceClient{
-		Client: client,
+		Client:  client,
+		BaseURL: baseURL,
+		Retry:   DefaultRetryPolicy(),
 	}
 }
 
@@ -94,14 +107,53 @@ type Indicators struct {
 	Quote    []Quote    `json:"quote"`
 	Adjclose []Adjclose `json:"adjclose"`
 }
+type DividendEvent struct {
+	Amount float64 `json:"amount"`
+	Date   int     `json:"date"`
+}
+type SplitEvent struct {
+	Date        int    `json:"date"`
+	Numerator   int64  `json:"numerator"`
+	Denominator int64  `json:"denominator"`
+	SplitRatio  string `json:"splitRatio"`
+}
+
+// Events are the corporate actions returned with events=div,splits, keyed by
+// their unix time.
+type Events struct {
+	Dividends map[string]DividendEvent `json:"dividends"`
+	Splits    map[string]SplitEvent    `json:"splits"`
+}
 type Result struct {
 	Meta       Meta       `json:"meta"`
 	Timestamp  []int      `json:"timestamp"`
+	Events     Events     `json:"events"`
 	Indicators Indicators `json:"indicators"`
 }
 type Chart struct {
-	Result []Result `json:"result"`
-	Error  any      `json:"error"`
+	Result []Result    `json:"result"`
+	Error  *ChartError `json:"error"`
+}
+
+// ChartError is the error object of the chart API, e.g. {"code": "Not Found",
+// "description": "No data found, symbol may be delisted"}.
+type ChartError struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+func (e *ChartError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+// describeChartError returns the chart error in the body of a failed
+// response, if there is one.
+func describeChartError(body []byte) error {
+	var chart ChartResponse
+	if err := json.Unmarshal(body, &chart); err != nil || chart.Chart.Error == nil {
+		return nil
+	}
+	return chart.Chart.Error
 }
 
 func IsSameLen[T any](array ...[]T) bool {
@@ -112,14 +164,17 @@ func IsSameLen[T any](array ...[]T) bool {
 	}
 	return true
 }
-func ConvertResponseToStock(res ChartResponse) (*Stocks, error) {
+
+// chartQuote returns the bars of the chart, checking that every column has
+// one value per timestamp.
+func chartQuote(res ChartResponse) (Result, Quote, error) {
 	result := res.Chart.Result
 	if len(result) == 0 {
-		return nil, fmt.Errorf("result is nil")
+		return Result{}, Quote{}, fmt.Errorf("result is nil")
 	}
 	quote := result[0].Indicators.Quote
 	if len(quote) == 0 {
-		return nil, fmt.Errorf("quote is nil")
+		return Result{}, Quote{}, fmt.Errorf("quote is nil")
 	}
 	timestamp := result[0].Timestamp
 	open := quote[0].Open
@@ -130,15 +185,35 @@ func ConvertResponseToStock(res ChartResponse) (*Stocks, error) {
 		logger.Error(
 			"same len error", "timestamp", len(timestamp), "open", len(open),
 			"close", len(close), "high", len(high), "low", len(low))
-		return nil, fmt.Errorf("don't same length error")
+		return Result{}, Quote{}, fmt.Errorf("don't same length error")
+	}
+	return result[0], quote[0], nil
+}
+
+func ConvertResponseToStock(res ChartResponse) (*Stocks, error) {
+	result, quote, err := chartQuote(res)
+	if err != nil {
+		return nil, err
+	}
+	symbol := result.Meta.Symbol
+	var adjclose []float64
+	if len(result.Indicators.Adjclose) > 0 {
+		adjclose = result.Indicators.Adjclose[0].Adjclose
 	}
-	symbol := result[0].Meta.Symbol
 
-	stocks := make([]Stock, 0, len(timestamp))
-	for i, t := range timestamp {
+	stocks := make([]Stock, 0, len(result.Timestamp))
+	for i, t := range result.Timestamp {
+		var options []StockOption
+		if len(quote.Volume) == len(result.Timestamp) {
+			options = append(options, WithStockVolume(int64(quote.Volume[i])))
+		}
+		if len(adjclose) == len(result.Timestamp) {
+			options = append(options, WithAdjClose(adjclose[i]))
+		}
 		stock, err := NewStock(
 			symbol, time.Unix(int64(t), 0),
-			open[i], close[i], high[i], low[i],
+			quote.Open[i], quote.Close[i], quote.High[i], quote.Low[i],
+			options...,
 		)
 		if err != nil {
 			logger.Error("new stock error", "error", err)
@@ -154,9 +229,56 @@ func ConvertResponseToStock(res ChartResponse) (*Stocks, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.dividends, s.splits = convertEvents(symbol, result.Events)
 	return s, nil
 }
 
+func convertEvents(symbol string, events Events) ([]Dividend, []Split) {
+	dividends := make([]Dividend, 0, len(events.Dividends))
+	for _, event := range events.Dividends {
+		dividend, err := NewDividend(symbol, time.Unix(int64(event.Date), 0), decimal.NewFromFloat(event.Amount))
+		if err != nil {
+			logger.Error("new dividend error", "error", err)
+			continue
+		}
+		dividends = append(dividends, dividend)
+	}
+	splits := make([]Split, 0, len(events.Splits))
+	for _, event := range events.Splits {
+		split, err := NewSplit(symbol, time.Unix(int64(event.Date), 0), event.Numerator, event.Denominator)
+		if err != nil {
+			logger.Error("new split error", "error", err)
+			continue
+		}
+		splits = append(splits, split)
+	}
+	slices.SortFunc(dividends, func(a, b Dividend) int { return a.ExDate.Compare(b.ExDate) })
+	slices.SortFunc(splits, func(a, b Split) int { return a.Date.Compare(b.Date) })
+	return dividends, splits
+}
+
+// ConvertResponseToIntradayStocks converts the chart into bars of the
+// interval. Bars without trades, which the chart API returns as nulls, are
+// skipped.
+func ConvertResponseToIntradayStocks(res ChartResponse, interval Interval) ([]IntradayStock, error) {
+	result, quote, err := chartQuote(res)
+	if err != nil {
+		return nil, err
+	}
+	stocks := make([]IntradayStock, 0, len(result.Timestamp))
+	for i, t := range result.Timestamp {
+		stock, err := NewIntradayStock(
+			result.Meta.Symbol, interval, time.Unix(int64(t), 0),
+			quote.Open[i], quote.Close[i], quote.High[i], quote.Low[i],
+		)
+		if err != nil {
+			continue
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks, nil
+}
+
 func ConvertResponseToSymbol(res *ChartResponse) (*SymbolDetail, error) {
 	result := res.Chart.Result
 	if len(result) == 0 {
@@ -168,8 +290,21 @@ func ConvertResponseToSymbol(res *ChartResponse) (*SymbolDetail, error) {
 	if err != nil {
 		return nil, err
 	}
+	options := []SymbolDetailOption{
+		WithExchangeName(meta.ExchangeName, meta.FullExchangeName),
+		WithInstrumentType(InstrumentType(strings.ToLower(meta.InstrumentType))),
+		WithTimezone(meta.ExchangeTimezoneName),
+		WithFiftyTwoWeekRange(
+			decimal.NewFromFloat(meta.FiftyTwoWeekLow), decimal.NewFromFloat(meta.FiftyTwoWeekHigh)),
+	}
+	if meta.FirstTradeDate != 0 {
+		options = append(options, WithFirstTradeDate(time.Unix(int64(meta.FirstTradeDate), 0).UTC()))
+	}
+	if exchange, ok := calendar.ExchangeFromYahoo(meta.ExchangeName, meta.ExchangeTimezoneName); ok {
+		options = append(options, WithExchange(exchange))
+	}
 	detail := NewSymbolDetail(meta.Symbol, meta.ShortName, meta.LongName, meta.Currency,
-		decimal.NewFromFloat(meta.RegularMarketPrice), decimal.NewFromFloat(previousClose))
+		decimal.NewFromFloat(meta.RegularMarketPrice), decimal.NewFromFloat(previousClose), options...)
 	return detail, nil
 }
 func parsePreviousClose(res *ChartResponse) (float64, error) {
@@ -181,6 +316,9 @@ func parsePreviousClose(res *ChartResponse) (float64, error) {
 	if meta.PreviousClose != 0 {
 		return meta.PreviousClose, nil
 	}
+	if close, ok := previousSessionClose(result[0]); ok {
+		return close, nil
+	}
 	adjclose := result[0].Indicators.Adjclose
 	if len(adjclose) == 0 {
 		return meta.ChartPreviousClose, nil
@@ -191,8 +329,43 @@ func parsePreviousClose(res *ChartResponse) (float64, error) {
 	return meta.ChartPreviousClose, nil
 }
 
+// previousSessionClose returns the close of the trading day before the one of
+// the market price. The last bar is not always that day: the chart ends with
+// the session in progress, and holidays leave no bar.
+func previousSessionClose(result Result) (float64, bool) {
+	exchange, ok := calendar.ExchangeFromYahoo(result.Meta.ExchangeName, result.Meta.ExchangeTimezoneName)
+	if !ok || len(result.Indicators.Quote) == 0 {
+		return 0, false
+	}
+	c, err := calendar.For(exchange)
+	if err != nil {
+		return 0, false
+	}
+	close := result.Indicators.Quote[0].Close
+	if len(close) != len(result.Timestamp) {
+		return 0, false
+	}
+	previous := c.PreviousTradingDay(c.Date(time.Unix(int64(result.Meta.RegularMarketTime), 0)))
+	for i := len(result.Timestamp) - 1; i >= 0; i-- {
+		if c.Date(time.Unix(int64(result.Timestamp[i]), 0)).Equal(previous) && close[i] > 0 {
+			return close[i], true
+		}
+	}
+	return 0, false
+}
+
 type Option func(URL *url.URL) *url.URL
 
+// WithEvents asks for corporate actions, e.g. "div,splits".
+func WithEvents(events string) Option {
+	return func(URL *url.URL) *url.URL {
+		query := URL.Query()
+		query.Add("events", events)
+		URL.RawQuery = query.Encode()
+		return URL
+	}
+}
+
 func WithInterval(interval string) Option {
 	return func(URL *url.URL) *url.URL {
 		query := URL.Query()
@@ -202,18 +375,60 @@ func WithInterval(interval string) Option {
 	}
 }
 
-func (c *FinanceClient) FetchCurrentStock(symbol string) (*Stocks, error) {
+func (c *FinanceClient) Name() string {
+	return "yahoo"
+}
+
+func (c *FinanceClient) FetchCurrentStock(ctx context.Context, symbol string) (*Stocks, error) {
 	now := time.Now()
-	return c.FetchStock(symbol, now, now)
+	return c.FetchChart(ctx, symbol, now, now)
 }
 
+// FetchStock fetches the daily prices of the symbol between start and end.
 func (c *FinanceClient) FetchStock(
-	symbol string, beggingOfPeriod, endOfPeriod time.Time, opts ...Option) (
+	ctx context.Context, symbol string, start, end time.Time) (*Stocks, error) {
+	return c.FetchChart(ctx, symbol, start, end, WithInterval("1d"), WithEvents("div,splits"))
+}
+
+// FetchChart fetches the chart of the symbol, shaped by opts.
+func (c *FinanceClient) FetchChart(
+	ctx context.Context, symbol string, beggingOfPeriod, endOfPeriod time.Time, opts ...Option) (
 	*Stocks, error) {
-	URL, err := url.Parse(fmt.Sprintf("https://query2.finance.yahoo.com/v8/finance/chart/%s", symbol))
+	chart, err := c.fetchChart(ctx, symbol, beggingOfPeriod, endOfPeriod, opts...)
 	if err != nil {
 		return nil, err
 	}
+	stocks, err := ConvertResponseToStock(chart)
+	if err != nil {
+		return nil, c.malformed(symbol, err)
+	}
+	return stocks, nil
+}
+
+// FetchIntraday fetches the bars of the symbol at the interval between start
+// and end.
+func (c *FinanceClient) FetchIntraday(
+	ctx context.Context, symbol string, interval Interval, start, end time.Time) (
+	[]IntradayStock, error) {
+	chart, err := c.fetchChart(ctx, symbol, start, end, WithInterval(interval.Param()))
+	if err != nil {
+		return nil, err
+	}
+	stocks, err := ConvertResponseToIntradayStocks(chart, interval)
+	if err != nil {
+		return nil, c.malformed(symbol, err)
+	}
+	return stocks, nil
+}
+
+func (c *FinanceClient) fetchChart(
+	ctx context.Context, symbol string, beggingOfPeriod, endOfPeriod time.Time, opts ...Option) (
+	ChartResponse, error) {
+	URL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ChartResponse{}, err
+	}
+	URL = URL.JoinPath("v8/finance/chart", symbol)
 	query := URL.Query()
 	query.Add("period1", strconv.Itoa(int(beggingOfPeriod.Unix())))
 	query.Add("period2", strconv.Itoa(int(endOfPeriod.Unix())))
@@ -224,26 +439,28 @@ func (c *FinanceClient) FetchStock(
 		URL = opt(URL)
 	}
 
-	logger.Info("request", "url", URL.String())
-	req, err := http.NewRequest(http.MethodGet, URL.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:136.0) Gecko/20100101 Firefox/136.0")
-	res, err := c.Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	logger.Info("request status", "code", res.StatusCode)
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
+	body, err := fetch(ctx, c.Client, c.Retry, c.Name(), symbol, URL.String(), describeChartError)
 	if err != nil {
-		return nil, err
+		return ChartResponse{}, err
 	}
 	var chart ChartResponse
 	if err := json.Unmarshal(body, &chart); err != nil {
-		return nil, err
+		return ChartResponse{}, c.malformed(symbol, err)
+	}
+	if chart.Chart.Error != nil {
+		kind := ErrUpstream
+		if chart.Chart.Error.Code == "Not Found" {
+			kind = ErrSymbolNotFound
+		}
+		return ChartResponse{}, &FetchError{
+			Provider: c.Name(), Symbol: symbol, StatusCode: http.StatusOK, Kind: kind, Err: chart.Chart.Error,
+		}
+	}
+	return chart, nil
+}
+
+func (c *FinanceClient) malformed(symbol string, err error) error {
+	return &FetchError{
+		Provider: c.Name(), Symbol: symbol, StatusCode: http.StatusOK, Kind: ErrMalformedPayload, Err: err,
 	}
-	time.Sleep(time.Second * 1)
-	return ConvertResponseToStock(chart)
 }
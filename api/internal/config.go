@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 
 	yaml "github.com/goccy/go-yaml"
 	"github.com/joho/godotenv"
@@ -73,6 +74,24 @@ func loadConfigFromEnv() (*Config, error) {
 		dbdsn = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", dbUser, dbPassword, dbHost, dbPort, dbName, dbSSLMode)
 	}
 
+	// 株価取得先（ローカルのスタブに向ける場合に上書き）
+	yahooBaseURL := getEnvOrDefault("YAHOO_BASE_URL", YahooFinanceBaseURL)
+	stooqBaseURL := getEnvOrDefault("STOOQ_BASE_URL", StooqBaseURL)
+	httpFixtureMode := FixtureMode(os.Getenv("HTTP_FIXTURE_MODE"))
+	if !httpFixtureMode.IsValid() {
+		return nil, fmt.Errorf("invalid HTTP_FIXTURE_MODE %q", httpFixtureMode)
+	}
+	httpFixtureDir := getEnvOrDefault("HTTP_FIXTURE_DIR", "testdata/http")
+	fetchRate, err := strconv.ParseFloat(getEnvOrDefault("FETCH_RATE", "2"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FETCH_RATE: %w", err)
+	}
+	fetchBurst, err := strconv.Atoi(getEnvOrDefault("FETCH_BURST", "4"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FETCH_BURST: %w", err)
+	}
+
+	webhookSecret := os.Getenv("WEBHOOK_SECRET")
 	logLevel := os.Getenv("LOG_LEVEL")
 	env := os.Getenv("APP_ENV")
 	if env == "" {
@@ -96,6 +115,13 @@ func loadConfigFromEnv() (*Config, error) {
 		OauthClientSecret: requiredEnvs["OAUTH_CLIENT_SECRET"],
 		OauthRedirectURL:  requiredEnvs["OAUTH_REDIRECT_URL"],
 		FrontendURL:       requiredEnvs["FRONTEND_URL"],
+		YahooBaseURL:      yahooBaseURL,
+		StooqBaseURL:      stooqBaseURL,
+		HTTPFixtureMode:   httpFixtureMode,
+		HTTPFixtureDir:    httpFixtureDir,
+		FetchRate:         fetchRate,
+		FetchBurst:        fetchBurst,
+		WebhookSecret:     webhookSecret,
 		LogLevel:          logLevel,
 		Environment:       env,
 	}, nil
@@ -118,6 +144,13 @@ type Config struct {
 	OauthClientSecret string
 	OauthRedirectURL  string
 	FrontendURL       string // フロントエンドのURLを追加
+	YahooBaseURL      string
+	StooqBaseURL      string
+	HTTPFixtureMode   FixtureMode // record: 実際のレスポンスを保存, replay: 保存済みのレスポンスを返す
+	HTTPFixtureDir    string
+	FetchRate         float64 // 株価APIへの1秒あたりのリクエスト数
+	FetchBurst        int
+	WebhookSecret     string // Webhook通知の署名に使う秘密鍵
 	LogLevel          string
 	Environment       string
 }
@@ -0,0 +1,175 @@
+package notifystock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/uptrace/bun"
+)
+
+type Dividend struct {
+	bun.BaseModel `bun:"table:dividends"`
+
+	Symbol string          `bun:"symbol,type:text,pk"`
+	ExDate time.Time       `bun:"ex_date,type:timestamp,pk"`
+	Amount decimal.Decimal `bun:"amount,type:decimal,notnull"`
+}
+
+func NewDividend(symbol string, exDate time.Time, amount decimal.Decimal) (Dividend, error) {
+	if !amount.IsPositive() {
+		return Dividend{}, fmt.Errorf("dividend amount must be positive. symbol: %v, amount: %v", symbol, amount)
+	}
+	return Dividend{
+		Symbol: symbol,
+		ExDate: exDate.Truncate(time.Hour * 24),
+		Amount: amount,
+	}, nil
+}
+
+// Split turns every Denominator shares into Numerator shares on Date.
+type Split struct {
+	bun.BaseModel `bun:"table:splits"`
+
+	Symbol      string       `bun:"symbol,type:text,pk"`
+	Date        time.Time    `bun:"date,type:timestamp,pk"`
+	Numerator   int64        `bun:"numerator,notnull"`
+	Denominator int64        `bun:"denominator,notnull"`
+	AdjustedAt  sql.NullTime `bun:"adjusted_at,type:timestamp"`
+}
+
+func NewSplit(symbol string, date time.Time, numerator, denominator int64) (Split, error) {
+	if numerator <= 0 || denominator <= 0 {
+		return Split{}, fmt.Errorf(
+			"split ratio must be positive. symbol: %v, numerator: %v, denominator: %v",
+			symbol, numerator, denominator)
+	}
+	return Split{
+		Symbol:      symbol,
+		Date:        date.Truncate(time.Hour * 24),
+		Numerator:   numerator,
+		Denominator: denominator,
+	}, nil
+}
+
+// Ratio returns how many shares one share becomes, e.g. 4 for a 4:1 split.
+func (s Split) Ratio() decimal.Decimal {
+	return decimal.NewFromInt(s.Numerator).Div(decimal.NewFromInt(s.Denominator))
+}
+
+func (s Split) String() string {
+	return fmt.Sprintf("%d:%d", s.Numerator, s.Denominator)
+}
+
+type CorporateActionRepository struct {
+	db *bun.DB
+}
+
+func NewCorporateActionRepository(db *bun.DB) *CorporateActionRepository {
+	return &CorporateActionRepository{
+		db: db,
+	}
+}
+
+func (r *CorporateActionRepository) SaveDividends(ctx context.Context, dividends []Dividend) error {
+	if len(dividends) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&dividends).
+		On("CONFLICT (symbol, ex_date) DO UPDATE").
+		Set("amount = EXCLUDED.amount").
+		Exec(ctx)
+	return err
+}
+
+// SaveSplits saves the splits, keeping when the known ones were adjusted.
+func (r *CorporateActionRepository) SaveSplits(ctx context.Context, splits []Split) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&splits).
+		ExcludeColumn("adjusted_at").
+		On("CONFLICT (symbol, date) DO NOTHING").
+		Exec(ctx)
+	return err
+}
+
+func (r *CorporateActionRepository) GetDividends(ctx context.Context, symbol string) ([]Dividend, error) {
+	var dividends []Dividend
+	if err := r.db.NewSelect().
+		Model(&dividends).
+		Where("symbol = ?", symbol).
+		Order("ex_date ASC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return dividends, nil
+}
+
+func (r *CorporateActionRepository) GetSplits(ctx context.Context, symbol string) ([]Split, error) {
+	var splits []Split
+	if err := r.db.NewSelect().
+		Model(&splits).
+		Where("symbol = ?", symbol).
+		Order("date ASC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return splits, nil
+}
+
+// AdjustSplits rewrites the prices before every split of the symbol that has
+// not been adjusted yet, dividing them by the split ratio and multiplying the
+// volume by it, so that they line up with the prices after the split. Only
+// the bars fetched before the split are rewritten, since the provider serves
+// the later ones already adjusted. It returns the adjusted splits.
+func (r *CorporateActionRepository) AdjustSplits(ctx context.Context, symbol string, now time.Time) ([]Split, error) {
+	var adjusted []Split
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var splits []Split
+		if err := tx.NewSelect().
+			Model(&splits).
+			Where("symbol = ?", symbol).
+			Where("adjusted_at IS NULL").
+			Order("date ASC").
+			For("UPDATE").
+			Scan(ctx); err != nil {
+			return err
+		}
+		for _, split := range splits {
+			ratio := split.Ratio()
+			if _, err := tx.NewUpdate().
+				Model((*Stock)(nil)).
+				Set("open = open / ?", ratio).
+				Set("close = close / ?", ratio).
+				Set("high = high / ?", ratio).
+				Set("low = low / ?", ratio).
+				Set("adj_close = adj_close / ?", ratio).
+				Set("volume = ROUND(volume * ?)", ratio).
+				Where("symbol = ?", split.Symbol).
+				Where("timestamp < ?", split.Date).
+				Where("fetched_at < ?", split.Date).
+				Exec(ctx); err != nil {
+				return err
+			}
+			split.AdjustedAt = sql.NullTime{Time: now, Valid: true}
+			if _, err := tx.NewUpdate().
+				Model(&split).
+				Column("adjusted_at").
+				WherePK().
+				Exec(ctx); err != nil {
+				return err
+			}
+			adjusted = append(adjusted, split)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjusted, nil
+}
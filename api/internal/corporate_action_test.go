@@ -0,0 +1,145 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestFetchCorporateActions(t *testing.T) {
+	client := &fixtureClient{fixtures: map[string]string{
+		yahooHost: "testdata/yahoo/chart_AAPL_events.json",
+	}}
+	start := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	stocks, err := notify.NewFinanceClient(client, notify.YahooFinanceBaseURL).
+		FetchStock(context.Background(), "AAPL", start, end)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "div,splits", client.requests[0].URL.Query().Get("events"))
+	assert.Len(t, stocks.Dividends(), 1)
+	dividend := stocks.Dividends()[0]
+	assert.Equal(t, "AAPL", dividend.Symbol)
+	assert.True(t, time.Date(2020, 8, 7, 0, 0, 0, 0, time.UTC).Equal(dividend.ExDate))
+	assert.Equal(t, "0.205", dividend.Amount.String())
+	assert.Len(t, stocks.Splits(), 1)
+	split := stocks.Splits()[0]
+	assert.True(t, time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC).Equal(split.Date))
+	assert.Equal(t, "4:1", split.String())
+	assert.True(t, decimal.NewFromInt(4).Equal(split.Ratio()))
+}
+
+func TestNewSplit(t *testing.T) {
+	split, err := notify.NewSplit("AAPL", time.Now(), 3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5", split.Ratio().String())
+
+	_, err = notify.NewSplit("AAPL", time.Now(), 0, 1)
+	assert.Error(t, err)
+	_, err = notify.NewDividend("AAPL", time.Now(), decimal.Zero)
+	assert.Error(t, err)
+}
+
+func TestCorporateActionRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := notify.NewCorporateActionRepository(db)
+	stockRepository := notify.NewStockRepository(db)
+	splitDate := time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC)
+
+	fetchedAt := splitDate.AddDate(0, 0, -1)
+	before, err := notify.NewStock("AAPL", splitDate.AddDate(0, 0, -3), 504, 500, 508, 496,
+		notify.WithStockVolume(100), notify.WithAdjClose(496), notify.WithFetchedAt(fetchedAt))
+	assert.NoError(t, err)
+	after, err := notify.NewStock("AAPL", splitDate, 127, 129, 131, 126,
+		notify.WithStockVolume(400), notify.WithFetchedAt(fetchedAt))
+	assert.NoError(t, err)
+	assert.NoError(t, stockRepository.Save(ctx, []notify.Stock{before, after}))
+
+	split, err := notify.NewSplit("AAPL", splitDate, 4, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SaveSplits(ctx, []notify.Split{split}))
+
+	t.Run("adjust prices before the split", func(t *testing.T) {
+		adjusted, err := repo.AdjustSplits(ctx, "AAPL", time.Now())
+		assert.NoError(t, err)
+		assert.Len(t, adjusted, 1)
+
+		stocks, err := stockRepository.GetStockByPeriod(ctx, "AAPL", splitDate.AddDate(0, 0, -7), splitDate)
+		assert.NoError(t, err)
+		assert.Len(t, stocks, 2)
+		assert.Equal(t, 125.0, stocks[0].Close)
+		assert.Equal(t, 126.0, stocks[0].Open)
+		assert.Equal(t, int64(400), stocks[0].Volume.Int64)
+		assert.Equal(t, 124.0, stocks[0].AdjClose.Float64)
+		assert.Equal(t, 129.0, stocks[1].Close)
+	})
+
+	t.Run("adjust every split once", func(t *testing.T) {
+		assert.NoError(t, repo.SaveSplits(ctx, []notify.Split{split}))
+
+		adjusted, err := repo.AdjustSplits(ctx, "AAPL", time.Now())
+
+		assert.NoError(t, err)
+		assert.Empty(t, adjusted)
+		splits, err := repo.GetSplits(ctx, "AAPL")
+		assert.NoError(t, err)
+		assert.True(t, splits[0].AdjustedAt.Valid)
+	})
+
+	t.Run("dividends", func(t *testing.T) {
+		dividend, err := notify.NewDividend("AAPL", splitDate.AddDate(0, 0, -24), decimal.RequireFromString("0.82"))
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveDividends(ctx, []notify.Dividend{dividend}))
+
+		dividends, err := repo.GetDividends(ctx, "AAPL")
+
+		assert.NoError(t, err)
+		assert.Len(t, dividends, 1)
+		assert.True(t, dividend.Amount.Equal(dividends[0].Amount))
+	})
+}
+
+func TestRegisterSplitAfterAdjustedChunk(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	stockRepository := notify.NewStockRepository(db)
+	register := notify.NewStockRegister(
+		notify.NewMarketDataProvider(&fixtureClient{fixtures: map[string]string{
+			yahooHost: "testdata/yahoo/chart_AAPL_events.json",
+		}}),
+		stockRepository,
+		notify.NewSymbolRepository(db),
+		notify.NewCorporateActionRepository(db),
+		notify.NewTrackedSymbolRepository(db),
+		notify.DefaultStockRegisterOption(),
+	)
+	// an earlier chunk of a backfill, fetched after the split and so already
+	// adjusted for it
+	earlier := time.Date(2020, 7, 31, 0, 0, 0, 0, time.UTC)
+	adjusted, err := notify.NewStock("AAPL", earlier, 102.88, 106.26, 106.42, 100.83,
+		notify.WithStockVolume(374336800), notify.WithAdjClose(104.47))
+	assert.NoError(t, err)
+	assert.NoError(t, stockRepository.Save(ctx, []notify.Stock{adjusted}))
+
+	err = register.RegisterStockBySymbol(ctx, "AAPL",
+		time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	splits, err := notify.NewCorporateActionRepository(db).GetSplits(ctx, "AAPL")
+	assert.NoError(t, err)
+	assert.Len(t, splits, 1)
+	assert.True(t, splits[0].AdjustedAt.Valid)
+	stocks, err := stockRepository.GetStockByPeriod(ctx, "AAPL", earlier, earlier.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Len(t, stocks, 1)
+	assert.Equal(t, 106.26, stocks[0].Close)
+	assert.Equal(t, 104.47, stocks[0].AdjClose.Float64)
+	assert.Equal(t, int64(374336800), stocks[0].Volume.Int64)
+}
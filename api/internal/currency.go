@@ -4,34 +4,133 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"unicode"
+
+	"github.com/shopspring/decimal"
 )
 
-//go:generate enumer -type=Currency
-type Currency int
+// Currency is an ISO 4217 currency code, e.g. "JPY".
+type Currency string
 
 const (
-	_ Currency = iota
-	JPY
-	USD
+	JPY Currency = "JPY"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	HKD Currency = "HKD"
 )
 
+type currencyInfo struct {
+	// symbol is the sign written before amounts, or empty when amounts are
+	// written with the code.
+	symbol string
+	// minorUnits is the number of decimal digits of the currency.
+	minorUnits int32
+}
+
+// currencies are the circulating currencies of ISO 4217, without the fund
+// codes and precious metals.
+var currencies = map[Currency]currencyInfo{
+	"AED": {"", 2}, "AFN": {"؋", 2}, "ALL": {"", 2}, "AMD": {"֏", 2},
+	"ANG": {"", 2}, "AOA": {"", 2}, "ARS": {"", 2}, "AUD": {"A$", 2},
+	"AWG": {"", 2}, "AZN": {"₼", 2}, "BAM": {"", 2}, "BBD": {"", 2},
+	"BDT": {"৳", 2}, "BGN": {"", 2}, "BHD": {"", 3}, "BIF": {"", 0},
+	"BMD": {"", 2}, "BND": {"", 2}, "BOB": {"", 2}, "BRL": {"R$", 2},
+	"BSD": {"", 2}, "BTN": {"", 2}, "BWP": {"", 2}, "BYN": {"", 2},
+	"BZD": {"", 2}, "CAD": {"CA$", 2}, "CDF": {"", 2}, "CHF": {"", 2},
+	"CLP": {"", 0}, "CNY": {"CN¥", 2}, "COP": {"", 2}, "CRC": {"₡", 2},
+	"CUP": {"", 2}, "CVE": {"", 2}, "CZK": {"", 2}, "DJF": {"", 0},
+	"DKK": {"", 2}, "DOP": {"", 2}, "DZD": {"", 2}, "EGP": {"", 2},
+	"ERN": {"", 2}, "ETB": {"", 2}, "EUR": {"€", 2}, "FJD": {"", 2},
+	"FKP": {"", 2}, "GBP": {"£", 2}, "GEL": {"₾", 2}, "GHS": {"₵", 2},
+	"GIP": {"", 2}, "GMD": {"", 2}, "GNF": {"", 0}, "GTQ": {"", 2},
+	"GYD": {"", 2}, "HKD": {"HK$", 2}, "HNL": {"", 2}, "HTG": {"", 2},
+	"HUF": {"", 2}, "IDR": {"", 2}, "ILS": {"₪", 2}, "INR": {"₹", 2},
+	"IQD": {"", 3}, "IRR": {"", 2}, "ISK": {"", 0}, "JMD": {"", 2},
+	"JOD": {"", 3}, "JPY": {"¥", 0}, "KES": {"", 2}, "KGS": {"", 2},
+	"KHR": {"", 2}, "KMF": {"", 0}, "KPW": {"", 2}, "KRW": {"₩", 0},
+	"KWD": {"", 3}, "KYD": {"", 2}, "KZT": {"₸", 2}, "LAK": {"", 2},
+	"LBP": {"", 2}, "LKR": {"", 2}, "LRD": {"", 2}, "LSL": {"", 2},
+	"LYD": {"", 3}, "MAD": {"", 2}, "MDL": {"", 2}, "MGA": {"", 2},
+	"MKD": {"", 2}, "MMK": {"", 2}, "MNT": {"₮", 2}, "MOP": {"", 2},
+	"MRU": {"", 2}, "MUR": {"", 2}, "MVR": {"", 2}, "MWK": {"", 2},
+	"MXN": {"MX$", 2}, "MYR": {"", 2}, "MZN": {"", 2}, "NAD": {"", 2},
+	"NGN": {"₦", 2}, "NIO": {"", 2}, "NOK": {"", 2}, "NPR": {"", 2},
+	"NZD": {"NZ$", 2}, "OMR": {"", 3}, "PAB": {"", 2}, "PEN": {"", 2},
+	"PGK": {"", 2}, "PHP": {"₱", 2}, "PKR": {"", 2}, "PLN": {"", 2},
+	"PYG": {"₲", 0}, "QAR": {"", 2}, "RON": {"", 2}, "RSD": {"", 2},
+	"RUB": {"₽", 2}, "RWF": {"", 0}, "SAR": {"", 2}, "SBD": {"", 2},
+	"SCR": {"", 2}, "SDG": {"", 2}, "SEK": {"", 2}, "SGD": {"S$", 2},
+	"SHP": {"", 2}, "SLE": {"", 2}, "SOS": {"", 2}, "SRD": {"", 2},
+	"SSP": {"", 2}, "STN": {"", 2}, "SVC": {"", 2}, "SYP": {"", 2},
+	"SZL": {"", 2}, "THB": {"฿", 2}, "TJS": {"", 2}, "TMT": {"", 2},
+	"TND": {"", 3}, "TOP": {"", 2}, "TRY": {"₺", 2}, "TTD": {"", 2},
+	"TWD": {"NT$", 2}, "TZS": {"", 2}, "UAH": {"₴", 2}, "UGX": {"", 0},
+	"USD": {"$", 2}, "UYU": {"", 2}, "UZS": {"", 2}, "VED": {"", 2},
+	"VES": {"", 2}, "VND": {"₫", 0}, "VUV": {"", 0}, "WST": {"", 2},
+	"XAF": {"", 0}, "XCD": {"", 2}, "XOF": {"", 0}, "XPF": {"", 0},
+	"YER": {"", 2}, "ZAR": {"", 2}, "ZMW": {"", 2}, "ZWG": {"", 2},
+}
+
+// ParseCurrency returns the currency of the code. The code is matched
+// exactly, so that the subunits of Yahoo Finance such as "GBp" (pence) are
+// not taken for their currency.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(code)
+	if !c.IsValid() {
+		return "", fmt.Errorf("%q is not a supported currency", code)
+	}
+	return c, nil
+}
+
+func (c Currency) IsValid() bool {
+	_, ok := currencies[c]
+	return ok
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// Symbol returns the sign of the currency, or its code when it has none.
 func (c Currency) Symbol() string {
-	cur := c.String()
-	switch cur {
-	case "JPY":
-		return "¥"
-	case "USD":
-		return "$"
-	default:
-		logger.Warn("unknown currency", "currency", cur)
-		return "$"
+	if info, ok := currencies[c]; ok && info.symbol != "" {
+		return info.symbol
+	}
+	return c.String()
+}
+
+// MinorUnits returns the number of decimal digits of the currency, 2 for an
+// unknown one.
+func (c Currency) MinorUnits() int32 {
+	if info, ok := currencies[c]; ok {
+		return info.minorUnits
+	}
+	return 2
+}
+
+// Round rounds d to the minor unit of the currency.
+func (c Currency) Round(d decimal.Decimal) decimal.Decimal {
+	return d.Round(c.MinorUnits())
+}
+
+// Format writes the formatted amount with the sign of the currency, e.g.
+// "$1,234.50", or with its code, e.g. "CHF 1,234.50".
+func (c Currency) Format(amount string) string {
+	symbol := []rune(c.Symbol())
+	if len(symbol) == 0 {
+		return amount
+	}
+	if unicode.IsLetter(symbol[len(symbol)-1]) {
+		return string(symbol) + " " + amount
 	}
+	return string(symbol) + amount
 }
 
 var _ driver.Valuer = (*Currency)(nil)
 
 func (c Currency) Value() (driver.Value, error) {
-	if c.IsACurrency() {
+	if c.IsValid() {
 		return c.String(), nil
 	}
 	return nil, nil
@@ -42,11 +141,10 @@ var _ sql.Scanner = (*Currency)(nil)
 func (c *Currency) Scan(value any) (err error) {
 	switch v := value.(type) {
 	case string:
-		*c, err = CurrencyString(v)
+		*c, err = ParseCurrency(v)
 		return err
 	case nil:
-		c = nil
-		return
+		return nil
 	default:
 		return fmt.Errorf("unsupported type %T for Currency", value)
 	}
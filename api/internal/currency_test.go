@@ -0,0 +1,51 @@
+package notifystock_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestParseCurrency(t *testing.T) {
+	for _, code := range []string{"JPY", "USD", "EUR", "GBP", "HKD", "KWD"} {
+		currency, err := notify.ParseCurrency(code)
+
+		assert.NoError(t, err, code)
+		assert.Equal(t, code, currency.String())
+	}
+	// pence are not pounds
+	for _, code := range []string{"GBp", "jpy", "XXX", ""} {
+		_, err := notify.ParseCurrency(code)
+
+		assert.Error(t, err, code)
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	tests := []struct {
+		currency   notify.Currency
+		symbol     string
+		minorUnits int32
+		rounded    string
+		formatted  string
+	}{
+		{notify.JPY, "¥", 0, "1235", "¥1,235"},
+		{notify.USD, "$", 2, "1234.57", "$1,234.57"},
+		{notify.HKD, "HK$", 2, "1234.57", "HK$1,234.57"},
+		{"KWD", "KWD", 3, "1234.567", "KWD 1,234.567"},
+		{"CHF", "CHF", 2, "1234.57", "CHF 1,234.57"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency.String(), func(t *testing.T) {
+			price := decimal.RequireFromString("1234.5674")
+
+			assert.Equal(t, tt.symbol, tt.currency.Symbol())
+			assert.Equal(t, tt.minorUnits, tt.currency.MinorUnits())
+			assert.Equal(t, tt.rounded, tt.currency.Round(price).String())
+			assert.Equal(t, tt.formatted, tt.currency.Format(notify.FormatNumber(price, tt.minorUnits)))
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package notifystock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+	// DeliveryStatusDead is a delivery given up after every attempt, which
+	// is never claimed again.
+	DeliveryStatusDead DeliveryStatus = "dead"
+)
+
+type DeliveryChannel string
+
+const (
+	DeliveryChannelEmail   DeliveryChannel = "email"
+	DeliveryChannelSlack   DeliveryChannel = "slack"
+	DeliveryChannelDiscord DeliveryChannel = "discord"
+	DeliveryChannelWebhook DeliveryChannel = "webhook"
+)
+
+func (c DeliveryChannel) IsValid() bool {
+	switch c {
+	case DeliveryChannelEmail, DeliveryChannelSlack, DeliveryChannelDiscord, DeliveryChannelWebhook:
+		return true
+	}
+	return false
+}
+
+type NotificationDelivery struct {
+	bun.BaseModel `bun:"table:notification_deliveries"`
+
+	ID             uuid.UUID       `bun:"id,type:uuid,pk"`
+	NotificationID uuid.UUID       `bun:"notification_id,type:uuid,notnull"`
+	ScheduledAt    time.Time       `bun:"scheduled_at,type:timestamp,notnull"`
+	Channel        DeliveryChannel `bun:"channel,type:text,notnull"`
+	Status         DeliveryStatus  `bun:"status,type:text,notnull"`
+	Response       string          `bun:"response,type:text,nullzero"`
+	Attempts       int             `bun:"attempts,notnull"`
+	Error          string          `bun:"error,type:text,nullzero"`
+	CreatedAt      time.Time       `bun:"created_at,type:timestamp,notnull,default:current_timestamp"`
+	UpdatedAt      time.Time       `bun:"updated_at,type:timestamp,notnull,default:current_timestamp"`
+}
+
+func NewNotificationDelivery(
+	ID *uuid.UUID, notificationID uuid.UUID, scheduledAt time.Time, channel DeliveryChannel,
+) (*NotificationDelivery, error) {
+	if ID == nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		ID = &id
+	}
+	now := time.Now()
+	return &NotificationDelivery{
+		ID:             *ID,
+		NotificationID: notificationID,
+		ScheduledAt:    NewDeliverySlot(scheduledAt),
+		Channel:        channel,
+		Status:         DeliveryStatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// NewDeliverySlot returns the hourly slot a delivery belongs to.
+func NewDeliverySlot(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Hour)
+}
+
+func (d *NotificationDelivery) Sent(response string) {
+	d.Status = DeliveryStatusSent
+	d.Response = response
+	d.Error = ""
+	d.Attempts++
+	d.UpdatedAt = time.Now()
+}
+
+func (d *NotificationDelivery) Failed(err error) {
+	d.Status = DeliveryStatusFailed
+	d.Error = err.Error()
+	d.Attempts++
+	d.UpdatedAt = time.Now()
+}
+
+// Dead records the last failed attempt of a delivery that is given up.
+func (d *NotificationDelivery) Dead(err error) {
+	d.Status = DeliveryStatusDead
+	d.Error = err.Error()
+	d.Attempts++
+	d.UpdatedAt = time.Now()
+}
+
+// Retrying records a failed attempt that will be retried.
+func (d *NotificationDelivery) Retrying(err error) {
+	d.Error = err.Error()
+	d.Attempts++
+	d.UpdatedAt = time.Now()
+}
+
+// DeliveryClaimLease is how long a pending delivery without an outbox
+// message is owned by the dispatcher that claimed it. A dispatcher that
+// crashed before queueing the message leaves such a delivery behind, which
+// can be claimed again after the lease.
+const DeliveryClaimLease = 10 * time.Minute
+
+type NotificationDeliveryRepository struct {
+	db *bun.DB
+}
+
+func NewNotificationDeliveryRepository(db *bun.DB) *NotificationDeliveryRepository {
+	return &NotificationDeliveryRepository{
+		db: db,
+	}
+}
+
+// Claim stores the delivery as pending unless the same notification, slot and
+// channel has already been claimed. A failed delivery can be claimed again,
+// and so can a pending one left without an outbox message for longer than
+// DeliveryClaimLease, but a dead one cannot.
+// It reports whether the caller owns the delivery; on success the delivery is
+// refreshed with the stored row.
+func (r *NotificationDeliveryRepository) Claim(
+	ctx context.Context, delivery *NotificationDelivery,
+) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(delivery).
+		On("CONFLICT (notification_id, scheduled_at, channel) DO UPDATE").
+		Set("status = EXCLUDED.status").
+		Set("updated_at = EXCLUDED.updated_at").
+		Where("notification_delivery.status = ?", DeliveryStatusFailed).
+		WhereOr("notification_delivery.status = ? AND notification_delivery.updated_at < ? "+
+			"AND NOT EXISTS (SELECT 1 FROM mail_outbox WHERE mail_outbox.delivery_id = notification_delivery.id)",
+			DeliveryStatusPending, delivery.UpdatedAt.Add(-DeliveryClaimLease)).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *NotificationDeliveryRepository) Update(ctx context.Context, delivery *NotificationDelivery) error {
+	_, err := r.db.NewUpdate().
+		Model(delivery).
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+func (r *NotificationDeliveryRepository) GetByID(
+	ctx context.Context, id uuid.UUID,
+) (*NotificationDelivery, error) {
+	var delivery NotificationDelivery
+	err := r.db.NewSelect().
+		Model(&delivery).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *NotificationDeliveryRepository) GetByNotificationID(
+	ctx context.Context, notificationID uuid.UUID,
+) ([]NotificationDelivery, error) {
+	var deliveries []NotificationDelivery
+	err := r.db.NewSelect().
+		Model(&deliveries).
+		Where("notification_id = ?", notificationID).
+		Order("scheduled_at DESC", "channel ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
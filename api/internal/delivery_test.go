@@ -0,0 +1,154 @@
+package notifystock_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestNotificationDeliveryRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	member := createMember(t, notify.NewMemberRepository(db))
+	symbol := notify.NewSymbolDetail("N225", "NIKKEI 225", "NIKKEI", "JPY", decimal.NewFromInt(1000), decimal.NewFromInt(1000))
+	if err := notify.NewSymbolRepository(db).Save(ctx, []notify.SymbolDetail{*symbol}); err != nil {
+		panic(err)
+	}
+	notification, err := notify.NewNotification(nil, member.ID, []string{"N225"}, time.Now())
+	assert.NoError(t, err)
+	if err := notify.NewNotificationRepository(db).Save(ctx, []notify.Notification{*notification}); err != nil {
+		panic(err)
+	}
+	repo := notify.NewNotificationDeliveryRepository(db)
+
+	t.Run("claim delivery once per slot", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 9, 15, 0, 0, time.UTC)
+		delivery, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+
+		claimed, err := repo.Claim(ctx, delivery)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		again, err := notify.NewNotificationDelivery(nil, notification.ID, now.Add(30*time.Minute), notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err = repo.Claim(ctx, again)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		deliveries, err := repo.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(deliveries))
+		assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), deliveries[0].ScheduledAt)
+		assert.Equal(t, notify.DeliveryStatusPending, deliveries[0].Status)
+	})
+
+	t.Run("failed delivery can be claimed again", func(t *testing.T) {
+		now := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+		delivery, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err := repo.Claim(ctx, delivery)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		delivery.Failed(fmt.Errorf("timeout"))
+		err = repo.Update(ctx, delivery)
+		assert.NoError(t, err)
+
+		retry, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err = repo.Claim(ctx, retry)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Equal(t, delivery.ID, retry.ID)
+		assert.Equal(t, 1, retry.Attempts)
+		assert.Equal(t, notify.DeliveryStatusPending, retry.Status)
+
+		retry.Sent("message-id")
+		err = repo.Update(ctx, retry)
+		assert.NoError(t, err)
+
+		again, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err = repo.Claim(ctx, again)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("stale pending delivery can be claimed again", func(t *testing.T) {
+		now := time.Date(2025, 1, 4, 9, 0, 0, 0, time.UTC)
+		delivery, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err := repo.Claim(ctx, delivery)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// the dispatcher crashed before queueing the message
+		delivery.UpdatedAt = delivery.UpdatedAt.Add(-notify.DeliveryClaimLease - time.Minute)
+		err = repo.Update(ctx, delivery)
+		assert.NoError(t, err)
+
+		retry, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err = repo.Claim(ctx, retry)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Equal(t, delivery.ID, retry.ID)
+	})
+
+	t.Run("queued pending delivery is not claimed again", func(t *testing.T) {
+		now := time.Date(2025, 1, 5, 9, 0, 0, 0, time.UTC)
+		delivery, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err := repo.Claim(ctx, delivery)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		message, err := notify.NewOutboxMessage(nil, &delivery.ID, notify.DeliveryChannelEmail,
+			"from@example.com", "to@example.com", "subject", "text")
+		assert.NoError(t, err)
+		err = notify.NewOutboxRepository(db).Save(ctx, []*notify.OutboxMessage{message})
+		assert.NoError(t, err)
+
+		delivery.UpdatedAt = delivery.UpdatedAt.Add(-notify.DeliveryClaimLease - time.Minute)
+		err = repo.Update(ctx, delivery)
+		assert.NoError(t, err)
+
+		retry, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err = repo.Claim(ctx, retry)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("dead delivery is not claimed again", func(t *testing.T) {
+		now := time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC)
+		delivery, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err := repo.Claim(ctx, delivery)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		delivery.Dead(fmt.Errorf("mailbox not found"))
+		err = repo.Update(ctx, delivery)
+		assert.NoError(t, err)
+
+		retry, err := notify.NewNotificationDelivery(nil, notification.ID, now, notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err = repo.Claim(ctx, retry)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+}
+
+func TestNewDeliverySlot(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	slot := notify.NewDeliverySlot(time.Date(2025, 1, 1, 18, 59, 59, 0, jst))
+
+	assert.Equal(t, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), slot)
+}
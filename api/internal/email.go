@@ -1,7 +1,9 @@
 package notifystock
 
 import (
+	"bytes"
 	"context"
+	"io"
 
 	"github.com/mailgun/mailgun-go/v5"
 )
@@ -26,18 +28,26 @@ func NewMailGunClient(config MailGunClientConfig) *MailGunClient {
 	}
 }
 
-func (m *MailGunClient) Send(from, to, subject, text string) error {
+// Send delivers the mail as multipart/alternative when it has an HTML body,
+// with the images the HTML refers to attached inline.
+func (m *MailGunClient) Send(mail Mail) (string, error) {
 	message := mailgun.NewMessage(
 		m.domain,
-		from,
-		subject,
-		text,
-		to,
+		mail.From,
+		mail.Subject,
+		mail.Text,
+		mail.To,
 	)
+	if mail.HTML != "" {
+		message.SetHTML(mail.HTML)
+		for _, image := range mail.Inline {
+			message.AddReaderInline(image.Name, io.NopCloser(bytes.NewReader(image.Data)))
+		}
+	}
 	res, err := m.mg.Send(context.Background(), message)
 	if err != nil {
-		return err
+		return "", err
 	}
 	logger.Info("send email success", "status", res)
-	return nil
+	return res.ID, nil
 }
@@ -0,0 +1,214 @@
+package notifystock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FXSymbol returns the Yahoo Finance symbol of the pair whose price is the
+// quote currency per base currency, e.g. "USDJPY=X".
+func FXSymbol(base, quote Currency) string {
+	return base.String() + quote.String() + "=X"
+}
+
+// rateLookback is how long before a time the last rate is looked up, so that
+// times on weekends and holidays take the rate of the last trading day.
+const rateLookback = 10 * 24 * time.Hour
+
+// ExchangeRates are the daily rates converting a currency into another over
+// a period.
+type ExchangeRates struct {
+	From Currency
+	To   Currency
+	legs []rateLeg
+}
+
+// rateLeg is a pair converted through, directly or inversely.
+type rateLeg struct {
+	closes  []Stock
+	inverse bool
+}
+
+func (l rateLeg) at(t time.Time) (decimal.Decimal, bool) {
+	i := sort.Search(len(l.closes), func(i int) bool {
+		return l.closes[i].Timestamp.After(t)
+	})
+	if i == 0 || t.Sub(l.closes[i-1].Timestamp) > rateLookback {
+		return decimal.Decimal{}, false
+	}
+	rate := decimal.NewFromFloat(l.closes[i-1].Close)
+	if rate.IsZero() {
+		return decimal.Decimal{}, false
+	}
+	if l.inverse {
+		return decimal.NewFromInt(1).Div(rate), true
+	}
+	return rate, true
+}
+
+// At returns the rate of the last close at or before t.
+func (r *ExchangeRates) At(t time.Time) (decimal.Decimal, bool) {
+	rate := decimal.NewFromInt(1)
+	for _, leg := range r.legs {
+		legRate, ok := leg.at(t)
+		if !ok {
+			return decimal.Decimal{}, false
+		}
+		rate = rate.Mul(legRate)
+	}
+	return rate, true
+}
+
+// ConvertStocks converts the prices of the bars by the rate of their day,
+// leaving out the bars without a rate. Volumes are kept as they are.
+func (r *ExchangeRates) ConvertStocks(stocks []Stock) []Stock {
+	converted := make([]Stock, 0, len(stocks))
+	for _, stock := range stocks {
+		rate, ok := r.At(stock.Timestamp)
+		if !ok {
+			continue
+		}
+		convert := func(price float64) float64 {
+			return decimal.NewFromFloat(price).Mul(rate).InexactFloat64()
+		}
+		stock.Open = convert(stock.Open)
+		stock.High = convert(stock.High)
+		stock.Low = convert(stock.Low)
+		stock.Close = convert(stock.Close)
+		if stock.AdjClose.Valid {
+			stock.AdjClose.Float64 = convert(stock.AdjClose.Float64)
+		}
+		converted = append(converted, stock)
+	}
+	return converted
+}
+
+// ConvertIntradayStocks converts the prices of the bars by the daily rate of
+// the last close before them, leaving out the bars without a rate.
+func (r *ExchangeRates) ConvertIntradayStocks(stocks []IntradayStock) []IntradayStock {
+	converted := make([]IntradayStock, 0, len(stocks))
+	for _, stock := range stocks {
+		rate, ok := r.At(stock.Timestamp)
+		if !ok {
+			continue
+		}
+		convert := func(price float64) float64 {
+			return decimal.NewFromFloat(price).Mul(rate).InexactFloat64()
+		}
+		stock.Open = convert(stock.Open)
+		stock.High = convert(stock.High)
+		stock.Low = convert(stock.Low)
+		stock.Close = convert(stock.Close)
+		converted = append(converted, stock)
+	}
+	return converted
+}
+
+// CurrencyConverter converts prices by the closes of the FX pairs, such as
+// USDJPY=X, registered like any other symbol.
+type CurrencyConverter struct {
+	stockRepository *StockRepository
+}
+
+func NewCurrencyConverter(stockRepository *StockRepository) *CurrencyConverter {
+	return &CurrencyConverter{
+		stockRepository: stockRepository,
+	}
+}
+
+// Rates returns the rates from start to end. A pair is used inversely when
+// only the opposite one is registered, and currencies without a pair between
+// them are converted through USD.
+func (c *CurrencyConverter) Rates(
+	ctx context.Context, from, to Currency, start, end time.Time) (*ExchangeRates, error) {
+	rates := &ExchangeRates{From: from, To: to}
+	if from == to {
+		return rates, nil
+	}
+	start = start.Add(-rateLookback)
+	leg, ok, err := c.leg(ctx, from, to, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		rates.legs = []rateLeg{leg}
+		return rates, nil
+	}
+	if from != USD && to != USD {
+		toUSD, fromOK, err := c.leg(ctx, from, USD, start, end)
+		if err != nil {
+			return nil, err
+		}
+		fromUSD, toOK, err := c.leg(ctx, USD, to, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if fromOK && toOK {
+			rates.legs = []rateLeg{toUSD, fromUSD}
+			return rates, nil
+		}
+	}
+	return nil, NewNotFoundError(fmt.Sprintf("Exchange rate from %s to %s", from, to))
+}
+
+func (c *CurrencyConverter) leg(
+	ctx context.Context, from, to Currency, start, end time.Time) (rateLeg, bool, error) {
+	direct, inverse := FXSymbol(from, to), FXSymbol(to, from)
+	stocks, err := c.stockRepository.GetStockByPeriodAndSymbols(
+		ctx, []string{direct, inverse}, start, end)
+	if err != nil {
+		return rateLeg{}, false, err
+	}
+	if closes := stocks[direct]; len(closes) > 0 {
+		return rateLeg{closes: closes}, true, nil
+	}
+	if closes := stocks[inverse]; len(closes) > 0 {
+		return rateLeg{closes: closes, inverse: true}, true, nil
+	}
+	return rateLeg{}, false, nil
+}
+
+// Convert converts the price by the rate of the last close at or before at.
+func (c *CurrencyConverter) Convert(
+	ctx context.Context, price decimal.Decimal, from, to Currency, at time.Time) (decimal.Decimal, error) {
+	rates, err := c.Rates(ctx, from, to, at, at)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	rate, ok := rates.At(at)
+	if !ok {
+		return decimal.Decimal{}, NewNotFoundError(fmt.Sprintf("Exchange rate from %s to %s", from, to))
+	}
+	return price.Mul(rate), nil
+}
+
+// ConvertSymbolDetail converts the prices of the detail by the rate of the
+// last close at or before at and reports them in to, so that its change is
+// shown in to as well.
+func (c *CurrencyConverter) ConvertSymbolDetail(
+	ctx context.Context, detail SymbolDetail, to Currency, at time.Time) (*SymbolDetail, error) {
+	if detail.Currency == nil {
+		return nil, NewValidationError("Unknown currency", fmt.Sprintf("currency of %s is unknown", detail.Symbol))
+	}
+	rates, err := c.Rates(ctx, *detail.Currency, to, at, at)
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := rates.At(at)
+	if !ok {
+		return nil, NewNotFoundError(fmt.Sprintf("Exchange rate from %s to %s", *detail.Currency, to))
+	}
+	detail.MarketPrice = detail.MarketPrice.Mul(rate)
+	detail.PreviousClose = detail.PreviousClose.Mul(rate)
+	if detail.FiftyTwoWeekLow.Valid && detail.FiftyTwoWeekHigh.Valid {
+		detail.FiftyTwoWeekLow.Decimal = detail.FiftyTwoWeekLow.Decimal.Mul(rate)
+		detail.FiftyTwoWeekHigh.Decimal = detail.FiftyTwoWeekHigh.Decimal.Mul(rate)
+	}
+	detail.Currency = &to
+	detail.roundPrices()
+	return &detail, nil
+}
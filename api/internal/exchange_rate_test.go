@@ -0,0 +1,108 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestFXSymbol(t *testing.T) {
+	assert.Equal(t, "USDJPY=X", notify.FXSymbol(notify.USD, notify.JPY))
+	assert.Equal(t, "EURUSD=X", notify.FXSymbol(notify.EUR, notify.USD))
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	stockRepository := notify.NewStockRepository(db)
+	converter := notify.NewCurrencyConverter(stockRepository)
+	thursday := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	friday := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)
+	var stocks []notify.Stock
+	for _, bar := range []struct {
+		symbol string
+		date   time.Time
+		close  float64
+	}{
+		{"USDJPY=X", thursday, 144},
+		{"USDJPY=X", friday, 145},
+		{"EURUSD=X", thursday, 1.09},
+		{"EURUSD=X", friday, 1.1},
+	} {
+		stock, err := notify.NewStock(bar.symbol, bar.date, bar.close, bar.close, bar.close, bar.close)
+		assert.NoError(t, err)
+		stocks = append(stocks, stock)
+	}
+	assert.NoError(t, stockRepository.Save(ctx, stocks))
+
+	tests := []struct {
+		name     string
+		from, to notify.Currency
+		at       time.Time
+		want     string
+	}{
+		{"direct", notify.USD, notify.JPY, thursday, "14400"},
+		{"last close on a weekend", notify.USD, notify.JPY, sunday, "14500"},
+		{"inverse", notify.JPY, notify.USD, friday, "0.8"},
+		{"through USD", notify.EUR, notify.JPY, friday, "15950"},
+		{"same currency", notify.JPY, notify.JPY, friday, "116"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := decimal.NewFromInt(100)
+			if tt.from == notify.JPY {
+				price = decimal.NewFromInt(116)
+			}
+
+			converted, err := converter.Convert(ctx, price, tt.from, tt.to, tt.at)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, converted.Round(2).String())
+		})
+	}
+
+	t.Run("no rate", func(t *testing.T) {
+		_, err := converter.Convert(ctx, decimal.NewFromInt(100), notify.USD, notify.JPY, thursday.AddDate(0, 0, -1))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("convert symbol detail", func(t *testing.T) {
+		gspc := notify.NewSymbolDetail("^GSPC", "S&P 500", "S&P 500", "USD",
+			decimal.RequireFromString("4700.25"), decimal.RequireFromString("4690.5"))
+
+		converted, err := converter.ConvertSymbolDetail(ctx, *gspc, notify.JPY, friday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, notify.JPY, *converted.Currency)
+		assert.Equal(t, "681536", converted.MarketPrice.String())
+		assert.Equal(t, "680123", converted.PreviousClose.String())
+		assert.Equal(t, "+1413", converted.Change())
+		assert.Equal(t, notify.USD, *gspc.Currency)
+	})
+
+	t.Run("convert stocks", func(t *testing.T) {
+		rates, err := converter.Rates(ctx, notify.USD, notify.JPY, thursday, sunday)
+		assert.NoError(t, err)
+		gspc := make([]notify.Stock, 0, 3)
+		for _, date := range []time.Time{thursday.AddDate(0, 0, -1), thursday, friday} {
+			stock, err := notify.NewStock("^GSPC", date, 4700, 4700, 4700, 4700, notify.WithStockVolume(100))
+			assert.NoError(t, err)
+			gspc = append(gspc, stock)
+		}
+
+		converted := rates.ConvertStocks(gspc)
+
+		// the bar before the first rate is left out
+		assert.Len(t, converted, 2)
+		assert.Equal(t, 676800.0, converted[0].Close)
+		assert.Equal(t, 681500.0, converted[1].Close)
+		assert.Equal(t, int64(100), converted[1].Volume.Int64)
+	})
+}
@@ -0,0 +1,183 @@
+package notifystock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrRateLimited      = errors.New("rate limited")
+	ErrSymbolNotFound   = errors.New("symbol not found")
+	ErrUpstream         = errors.New("upstream error")
+	ErrMalformedPayload = errors.New("malformed payload")
+)
+
+// FetchError is the failure of a market data provider to return the prices of
+// a symbol. Kind is one of the Err* sentinels above, so callers can test it
+// with errors.Is.
+type FetchError struct {
+	Provider   string
+	Symbol     string
+	StatusCode int
+	RetryAfter time.Duration
+	Kind       error
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	msg := fmt.Sprintf("%s %s: %v", e.Provider, e.Symbol, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *FetchError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Temporary reports whether another attempt may succeed: rate limits, server
+// errors and failures to reach the provider at all.
+func (e *FetchError) Temporary() bool {
+	switch e.Kind {
+	case ErrRateLimited:
+		return true
+	case ErrUpstream:
+		return e.StatusCode == 0 || e.StatusCode >= 500
+	}
+	return false
+}
+
+// ClassifyStatus returns the error of a non-200 response, or nil.
+func ClassifyStatus(provider, symbol string, res *http.Response, now time.Time) *FetchError {
+	err := &FetchError{Provider: provider, Symbol: symbol, StatusCode: res.StatusCode}
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil
+	case res.StatusCode == http.StatusTooManyRequests:
+		err.Kind = ErrRateLimited
+		err.RetryAfter = ParseRetryAfter(res.Header.Get("Retry-After"), now)
+	case res.StatusCode == http.StatusNotFound:
+		err.Kind = ErrSymbolNotFound
+	case res.StatusCode == http.StatusServiceUnavailable:
+		err.Kind = ErrUpstream
+		err.RetryAfter = ParseRetryAfter(res.Header.Get("Retry-After"), now)
+	default:
+		err.Kind = ErrUpstream
+	}
+	return err
+}
+
+// ParseRetryAfter parses the Retry-After header, given either in seconds or
+// as an HTTP date. It returns zero when the header is missing or invalid.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     Backoff
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     Backoff{Base: 500 * time.Millisecond, Max: 30 * time.Second},
+	}
+}
+
+// Delay returns the wait after the failed attempt: the Retry-After of the
+// response when given, otherwise the backoff delay with jitter between half
+// and all of it. It never exceeds Backoff.Max.
+func (p RetryPolicy) Delay(attempts int, err error) time.Duration {
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) && fetchErr.RetryAfter > 0 {
+		return min(fetchErr.RetryAfter, p.Backoff.Max)
+	}
+	delay := p.Backoff.Delay(attempts)
+	if half := int64(delay / 2); half > 0 {
+		return time.Duration(half + rand.Int64N(half+1))
+	}
+	return delay
+}
+
+// fetch GETs the URL, retrying temporary failures by the policy, and returns
+// the body of the 200 response. describe extracts the error message of the
+// provider from the body of a failed response.
+func fetch(
+	ctx context.Context, client HTTPClientInterface, policy RetryPolicy,
+	provider, symbol, URL string, describe func(body []byte) error,
+) ([]byte, error) {
+	for attempts := 1; ; attempts++ {
+		body, err := fetchOnce(ctx, client, provider, symbol, URL, describe)
+		var fetchErr *FetchError
+		if err == nil || !errors.As(err, &fetchErr) || !fetchErr.Temporary() ||
+			attempts >= policy.MaxAttempts || ctx.Err() != nil {
+			return body, err
+		}
+		delay := policy.Delay(attempts, err)
+		logger.Warn("retrying request", "url", URL, "attempts", attempts, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func fetchOnce(
+	ctx context.Context, client HTTPClientInterface,
+	provider, symbol, URL string, describe func(body []byte) error,
+) ([]byte, error) {
+	logger.Info("request", "url", URL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", userAgent)
+	res, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &FetchError{Provider: provider, Symbol: symbol, Kind: ErrUpstream, Err: err}
+	}
+	logger.Info("request status", "code", res.StatusCode)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, &FetchError{
+			Provider: provider, Symbol: symbol, StatusCode: res.StatusCode, Kind: ErrUpstream, Err: err,
+		}
+	}
+	if fetchErr := ClassifyStatus(provider, symbol, res, time.Now()); fetchErr != nil {
+		if describe != nil {
+			fetchErr.Err = describe(body)
+		}
+		return nil, fetchErr
+	}
+	return body, nil
+}
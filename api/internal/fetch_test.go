@@ -0,0 +1,172 @@
+package notifystock_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// newScriptedServer replies with the responses in order, repeating the last
+// one, and counts the requests.
+func newScriptedServer(t *testing.T, responses ...scriptedResponse) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := responses[min(requests, len(responses)-1)]
+		requests++
+		if res.retryAfter != "" {
+			w.Header().Set("Retry-After", res.retryAfter)
+		}
+		w.WriteHeader(res.status)
+		w.Write([]byte(res.body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestFinanceClientErrors(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	chart, err := os.ReadFile("testdata/yahoo/chart_N225.json")
+	assert.NoError(t, err)
+	notFound := `{"chart":{"result":null,"error":{"code":"Not Found","description":"No data found, symbol may be delisted"}}}`
+	newClient := func(server *httptest.Server) *notify.FinanceClient {
+		client := notify.NewFinanceClient(server.Client(), server.URL)
+		client.Retry = notify.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     notify.Backoff{Base: time.Millisecond, Max: 5 * time.Millisecond},
+		}
+		return client
+	}
+
+	t.Run("retry transient errors", func(t *testing.T) {
+		server, requests := newScriptedServer(t,
+			scriptedResponse{status: http.StatusTooManyRequests, retryAfter: "1"},
+			scriptedResponse{status: http.StatusBadGateway},
+			scriptedResponse{status: http.StatusOK, body: string(chart)},
+		)
+
+		stocks, err := newClient(server).FetchStock(ctx, "^N225", now, now)
+
+		assert.NoError(t, err)
+		assertN225(t, stocks)
+		assert.Equal(t, 3, *requests)
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		server, requests := newScriptedServer(t,
+			scriptedResponse{status: http.StatusInternalServerError, body: "oops"},
+		)
+
+		_, err := newClient(server).FetchStock(ctx, "^N225", now, now)
+
+		assert.ErrorIs(t, err, notify.ErrUpstream)
+		var fetchErr *notify.FetchError
+		assert.True(t, errors.As(err, &fetchErr))
+		assert.Equal(t, http.StatusInternalServerError, fetchErr.StatusCode)
+		assert.Equal(t, 3, *requests)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		server, requests := newScriptedServer(t,
+			scriptedResponse{status: http.StatusTooManyRequests, retryAfter: "1"},
+		)
+
+		_, err := newClient(server).FetchStock(ctx, "^N225", now, now)
+
+		assert.ErrorIs(t, err, notify.ErrRateLimited)
+		assert.Equal(t, 3, *requests)
+	})
+
+	t.Run("symbol not found is not retried", func(t *testing.T) {
+		server, requests := newScriptedServer(t,
+			scriptedResponse{status: http.StatusNotFound, body: notFound},
+		)
+
+		_, err := newClient(server).FetchStock(ctx, "^XXX", now, now)
+
+		assert.ErrorIs(t, err, notify.ErrSymbolNotFound)
+		var chartErr *notify.ChartError
+		assert.True(t, errors.As(err, &chartErr))
+		assert.Equal(t, "No data found, symbol may be delisted", chartErr.Description)
+		assert.Equal(t, 1, *requests)
+	})
+
+	t.Run("chart error in a 200 response", func(t *testing.T) {
+		server, _ := newScriptedServer(t,
+			scriptedResponse{status: http.StatusOK, body: notFound},
+		)
+
+		_, err := newClient(server).FetchStock(ctx, "^XXX", now, now)
+
+		assert.ErrorIs(t, err, notify.ErrSymbolNotFound)
+	})
+
+	t.Run("malformed payload", func(t *testing.T) {
+		server, requests := newScriptedServer(t,
+			scriptedResponse{status: http.StatusOK, body: "<html>"},
+		)
+
+		_, err := newClient(server).FetchStock(ctx, "^N225", now, now)
+
+		assert.ErrorIs(t, err, notify.ErrMalformedPayload)
+		assert.Equal(t, 1, *requests)
+	})
+
+	t.Run("stooq without data", func(t *testing.T) {
+		server, _ := newScriptedServer(t,
+			scriptedResponse{status: http.StatusOK, body: "No data"},
+		)
+
+		_, err := notify.NewStooqClient(server.Client(), server.URL).FetchStock(ctx, "^XXX", now, now)
+
+		assert.ErrorIs(t, err, notify.ErrSymbolNotFound)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 2*time.Minute, notify.ParseRetryAfter("120", now))
+	assert.Equal(t, 30*time.Second, notify.ParseRetryAfter("Sun, 18 Oct 2026 09:00:30 GMT", now))
+	assert.Equal(t, time.Duration(0), notify.ParseRetryAfter("Sun, 18 Oct 2026 08:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), notify.ParseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), notify.ParseRetryAfter("soon", now))
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := notify.RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     notify.Backoff{Base: time.Second, Max: time.Minute},
+	}
+
+	t.Run("jitter", func(t *testing.T) {
+		for range 100 {
+			delay := policy.Delay(3, errors.New("down"))
+			assert.GreaterOrEqual(t, delay, 2*time.Second)
+			assert.LessOrEqual(t, delay, 4*time.Second)
+		}
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		err := &notify.FetchError{Kind: notify.ErrRateLimited, RetryAfter: 10 * time.Second}
+		assert.Equal(t, 10*time.Second, policy.Delay(1, err))
+
+		err.RetryAfter = time.Hour
+		assert.Equal(t, time.Minute, policy.Delay(1, err))
+	})
+}
@@ -0,0 +1,143 @@
+package notifystock
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type FixtureMode string
+
+const (
+	FixtureModeOff    FixtureMode = ""
+	FixtureModeRecord FixtureMode = "record"
+	FixtureModeReplay FixtureMode = "replay"
+)
+
+func (m FixtureMode) IsValid() bool {
+	return slices.Contains(
+		[]FixtureMode{FixtureModeOff, FixtureModeRecord, FixtureModeReplay}, m)
+}
+
+// volatileParams are the query parameters left out of the fixture name, so
+// that a response recorded on one day is replayed for a later period.
+var volatileParams = []string{"period1", "period2", "d1", "d2"}
+
+type recordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// FixtureClient records the responses of the client under dir, or replays the
+// recorded ones without touching the network.
+type FixtureClient struct {
+	client HTTPClientInterface
+	dir    string
+	mode   FixtureMode
+}
+
+func NewFixtureClient(client HTTPClientInterface, dir string, mode FixtureMode) *FixtureClient {
+	return &FixtureClient{
+		client: client,
+		dir:    dir,
+		mode:   mode,
+	}
+}
+
+// NewHTTPClient returns the client used to fetch market data. Its requests
+// share one rate limiter, and it is wrapped in a FixtureClient when
+// HTTP_FIXTURE_MODE is set.
+func NewHTTPClient() HTTPClientInterface {
+	client := NewRateLimitedClient(
+		&http.Client{}, NewRateLimiter(Cfg.FetchRate, Cfg.FetchBurst),
+	)
+	if Cfg.HTTPFixtureMode == FixtureModeOff {
+		return client
+	}
+	return NewFixtureClient(client, Cfg.HTTPFixtureDir, Cfg.HTTPFixtureMode)
+}
+
+func (c *FixtureClient) Do(req *http.Request) (*http.Response, error) {
+	switch c.mode {
+	case FixtureModeRecord:
+		return c.record(req)
+	case FixtureModeReplay:
+		return c.replay(req)
+	}
+	return c.client.Do(req)
+}
+
+// Path returns the file the response to req is recorded in, e.g.
+// "<dir>/stooq.com/q_d_l_3f2a9c01.json".
+func (c *FixtureClient) Path(req *http.Request) string {
+	query := req.URL.Query()
+	for _, param := range volatileParams {
+		query.Del(param)
+	}
+	sum := sha256.Sum256([]byte(req.Method + " " + query.Encode()))
+	name := strings.ReplaceAll(strings.Trim(req.URL.Path, "/"), "/", "_")
+	return filepath.Join(c.dir, req.URL.Host, name+"_"+hex.EncodeToString(sum[:4])+".json")
+}
+
+func (c *FixtureClient) record(req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := json.MarshalIndent(recordedResponse{
+		Status: res.StatusCode,
+		Header: res.Header,
+		Body:   string(body),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := c.Path(req)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		return nil, err
+	}
+	logger.Info("recorded response", "url", req.URL.String(), "path", path)
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+func (c *FixtureClient) replay(req *http.Request) (*http.Response, error) {
+	path := c.Path(req)
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded response for %s at %s", req.URL, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var recorded recordedResponse
+	if err := json.Unmarshal(buf, &recorded); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode: recorded.Status,
+		Header:     recorded.Header,
+		Body:       io.NopCloser(bytes.NewBufferString(recorded.Body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package notifystock_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestFixtureClient(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("Date,Open,High,Low,Close,Volume\n"))
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, client notify.HTTPClientInterface, URL string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+		assert.NoError(t, err)
+		res, err := client.Do(req)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		return res, string(body)
+	}
+
+	t.Run("record", func(t *testing.T) {
+		client := notify.NewFixtureClient(server.Client(), dir, notify.FixtureModeRecord)
+
+		res, body := get(t, client, server.URL+"/q/d/l/?s=%5Enkx&d1=20240104&d2=20240109")
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "Date,Open,High,Low,Close,Volume\n", body)
+		assert.Equal(t, 1, hits)
+	})
+
+	t.Run("replay ignores the period", func(t *testing.T) {
+		client := notify.NewFixtureClient(server.Client(), dir, notify.FixtureModeReplay)
+
+		res, body := get(t, client, server.URL+"/q/d/l/?s=%5Enkx&d1=20250104&d2=20250109")
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+		assert.Equal(t, "Date,Open,High,Low,Close,Volume\n", body)
+		assert.Equal(t, 1, hits)
+	})
+
+	t.Run("replay without a recording", func(t *testing.T) {
+		client := notify.NewFixtureClient(server.Client(), dir, notify.FixtureModeReplay)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/q/d/l/?s=%5Espx", nil)
+		assert.NoError(t, err)
+
+		_, err = client.Do(req)
+
+		assert.ErrorContains(t, err, "no recorded response")
+		assert.Equal(t, 1, hits)
+	})
+}
@@ -0,0 +1,395 @@
+package notifystock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type IndicatorKind string
+
+const (
+	IndicatorSMA        IndicatorKind = "sma"
+	IndicatorEMA        IndicatorKind = "ema"
+	IndicatorRSI        IndicatorKind = "rsi"
+	IndicatorMACD       IndicatorKind = "macd"
+	IndicatorBollinger  IndicatorKind = "bollinger"
+	IndicatorATR        IndicatorKind = "atr"
+	IndicatorVolatility IndicatorKind = "volatility"
+)
+
+// tradingDaysPerYear annualizes the daily volatility.
+const tradingDaysPerYear = 252
+
+type IndicatorParams struct {
+	// Period is the window of SMA, EMA, RSI, Bollinger bands, ATR and
+	// volatility in trading days.
+	Period int
+	// FastPeriod, SlowPeriod and SignalPeriod are the MACD windows.
+	FastPeriod   int
+	SlowPeriod   int
+	SignalPeriod int
+	// StdDev is the width of the Bollinger bands in standard deviations.
+	StdDev decimal.Decimal
+}
+
+// WithDefaults fills the unset parameters with the common defaults of kind.
+func (p IndicatorParams) WithDefaults(kind IndicatorKind) IndicatorParams {
+	if p.Period == 0 {
+		switch kind {
+		case IndicatorRSI, IndicatorATR:
+			p.Period = 14
+		default:
+			p.Period = 20
+		}
+	}
+	if p.FastPeriod == 0 {
+		p.FastPeriod = 12
+	}
+	if p.SlowPeriod == 0 {
+		p.SlowPeriod = 26
+	}
+	if p.SignalPeriod == 0 {
+		p.SignalPeriod = 9
+	}
+	if p.StdDev.IsZero() {
+		p.StdDev = decimal.NewFromInt(2)
+	}
+	return p
+}
+
+// Lookback returns how many trading days before the first point are needed
+// to compute kind.
+func (p IndicatorParams) Lookback(kind IndicatorKind) int {
+	switch kind {
+	case IndicatorMACD:
+		return p.SlowPeriod + p.SignalPeriod
+	case IndicatorRSI, IndicatorATR, IndicatorVolatility:
+		return p.Period + 1
+	}
+	return p.Period
+}
+
+// IndicatorPoint is a value of an indicator on a trading day. Bollinger bands
+// set Upper and Lower around the middle band in Value, and MACD sets Signal
+// and Histogram.
+type IndicatorPoint struct {
+	Timestamp time.Time
+	Value     decimal.Decimal
+	Upper     decimal.NullDecimal
+	Lower     decimal.NullDecimal
+	Signal    decimal.NullDecimal
+	Histogram decimal.NullDecimal
+}
+
+func (s *Stocks) Indicator(kind IndicatorKind, params IndicatorParams) ([]IndicatorPoint, error) {
+	params = params.WithDefaults(kind)
+	switch kind {
+	case IndicatorSMA:
+		return s.SMA(params.Period)
+	case IndicatorEMA:
+		return s.EMA(params.Period)
+	case IndicatorRSI:
+		return s.RSI(params.Period)
+	case IndicatorMACD:
+		return s.MACD(params.FastPeriod, params.SlowPeriod, params.SignalPeriod)
+	case IndicatorBollinger:
+		return s.BollingerBands(params.Period, params.StdDev)
+	case IndicatorATR:
+		return s.ATR(params.Period)
+	case IndicatorVolatility:
+		return s.Volatility(params.Period)
+	}
+	return nil, NewValidationError("Unsupported indicator", fmt.Sprintf("indicator %q is not supported", kind))
+}
+
+// SMA returns the simple moving average of the closes.
+func (s *Stocks) SMA(period int) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if err := checkPeriod(period, len(stocks)); err != nil {
+		return nil, err
+	}
+	closes := closesOf(stocks)
+	points := make([]IndicatorPoint, 0, len(stocks)-period+1)
+	sum := decimal.Zero
+	for i, close := range closes {
+		sum = sum.Add(close)
+		if i >= period {
+			sum = sum.Sub(closes[i-period])
+		}
+		if i >= period-1 {
+			points = append(points, IndicatorPoint{
+				Timestamp: stocks[i].Timestamp,
+				Value:     sum.Div(decimal.NewFromInt(int64(period))),
+			})
+		}
+	}
+	return points, nil
+}
+
+// EMA returns the exponential moving average of the closes, seeded with the
+// simple average of the first period closes.
+func (s *Stocks) EMA(period int) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if err := checkPeriod(period, len(stocks)); err != nil {
+		return nil, err
+	}
+	values := ema(closesOf(stocks), period)
+	points := make([]IndicatorPoint, 0, len(values))
+	for i, value := range values {
+		points = append(points, IndicatorPoint{
+			Timestamp: stocks[i+period-1].Timestamp,
+			Value:     value,
+		})
+	}
+	return points, nil
+}
+
+// RSI returns the relative strength index with Wilder's smoothing.
+func (s *Stocks) RSI(period int) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if err := checkPeriod(period, len(stocks)-1); err != nil {
+		return nil, err
+	}
+	closes := closesOf(stocks)
+	p := decimal.NewFromInt(int64(period))
+	hundred := decimal.NewFromInt(100)
+	var gain, loss decimal.Decimal
+	points := make([]IndicatorPoint, 0, len(stocks)-period)
+	for i := 1; i < len(closes); i++ {
+		change := closes[i].Sub(closes[i-1])
+		up, down := decimal.Max(change, decimal.Zero), decimal.Max(change.Neg(), decimal.Zero)
+		if i <= period {
+			gain, loss = gain.Add(up), loss.Add(down)
+			if i < period {
+				continue
+			}
+			gain, loss = gain.Div(p), loss.Div(p)
+		} else {
+			gain = gain.Mul(p.Sub(decimal.NewFromInt(1))).Add(up).Div(p)
+			loss = loss.Mul(p.Sub(decimal.NewFromInt(1))).Add(down).Div(p)
+		}
+		value := hundred
+		if !loss.IsZero() {
+			value = hundred.Sub(hundred.Div(gain.Div(loss).Add(decimal.NewFromInt(1))))
+		}
+		points = append(points, IndicatorPoint{
+			Timestamp: stocks[i].Timestamp,
+			Value:     value,
+		})
+	}
+	return points, nil
+}
+
+// MACD returns the difference of the fast and slow EMA with its signal line.
+func (s *Stocks) MACD(fast, slow, signal int) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if fast <= 0 || signal <= 0 || fast >= slow {
+		return nil, NewValidationError("Invalid MACD periods",
+			"periods must be greater than zero and fast period must be less than slow period")
+	}
+	if err := checkPeriod(slow+signal-1, len(stocks)); err != nil {
+		return nil, err
+	}
+	closes := closesOf(stocks)
+	fastEMA, slowEMA := ema(closes, fast), ema(closes, slow)
+	macd := make([]decimal.Decimal, 0, len(slowEMA))
+	for i, value := range slowEMA {
+		macd = append(macd, fastEMA[i+slow-fast].Sub(value))
+	}
+	signals := ema(macd, signal)
+	points := make([]IndicatorPoint, 0, len(signals))
+	for i, value := range signals {
+		line := macd[i+signal-1]
+		points = append(points, IndicatorPoint{
+			Timestamp: stocks[i+slow+signal-2].Timestamp,
+			Value:     line,
+			Signal:    decimal.NewNullDecimal(value),
+			Histogram: decimal.NewNullDecimal(line.Sub(value)),
+		})
+	}
+	return points, nil
+}
+
+// BollingerBands returns the simple moving average with bands k population
+// standard deviations above and below it.
+func (s *Stocks) BollingerBands(period int, k decimal.Decimal) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if err := checkPeriod(period, len(stocks)); err != nil {
+		return nil, err
+	}
+	closes := closesOf(stocks)
+	points := make([]IndicatorPoint, 0, len(stocks)-period+1)
+	for i := period - 1; i < len(closes); i++ {
+		mean, stddev, err := meanStdDev(closes[i-period+1 : i+1])
+		if err != nil {
+			return nil, err
+		}
+		width := stddev.Mul(k)
+		points = append(points, IndicatorPoint{
+			Timestamp: stocks[i].Timestamp,
+			Value:     mean,
+			Upper:     decimal.NewNullDecimal(mean.Add(width)),
+			Lower:     decimal.NewNullDecimal(mean.Sub(width)),
+		})
+	}
+	return points, nil
+}
+
+// ATR returns the average true range with Wilder's smoothing.
+func (s *Stocks) ATR(period int) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if err := checkPeriod(period, len(stocks)-1); err != nil {
+		return nil, err
+	}
+	p := decimal.NewFromInt(int64(period))
+	var atr decimal.Decimal
+	points := make([]IndicatorPoint, 0, len(stocks)-period)
+	for i := 1; i < len(stocks); i++ {
+		high := decimal.NewFromFloat(stocks[i].High)
+		low := decimal.NewFromFloat(stocks[i].Low)
+		previous := decimal.NewFromFloat(stocks[i-1].Close)
+		tr := decimal.Max(high.Sub(low), high.Sub(previous).Abs(), low.Sub(previous).Abs())
+		if i <= period {
+			atr = atr.Add(tr)
+			if i < period {
+				continue
+			}
+			atr = atr.Div(p)
+		} else {
+			atr = atr.Mul(p.Sub(decimal.NewFromInt(1))).Add(tr).Div(p)
+		}
+		points = append(points, IndicatorPoint{
+			Timestamp: stocks[i].Timestamp,
+			Value:     atr,
+		})
+	}
+	return points, nil
+}
+
+// Volatility returns the annualized standard deviation of the daily returns
+// over period trading days.
+func (s *Stocks) Volatility(period int) ([]IndicatorPoint, error) {
+	stocks := s.sorted()
+	if err := checkPeriod(period, len(stocks)-1); err != nil {
+		return nil, err
+	}
+	closes := closesOf(stocks)
+	returns := make([]decimal.Decimal, 0, len(closes)-1)
+	for i := 1; i < len(closes); i++ {
+		returns = append(returns, closes[i].Div(closes[i-1]).Sub(decimal.NewFromInt(1)))
+	}
+	annualize, err := sqrt(decimal.NewFromInt(tradingDaysPerYear))
+	if err != nil {
+		return nil, err
+	}
+	points := make([]IndicatorPoint, 0, len(returns)-period+1)
+	for i := period - 1; i < len(returns); i++ {
+		_, stddev, err := meanStdDev(returns[i-period+1 : i+1])
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, IndicatorPoint{
+			Timestamp: stocks[i+1].Timestamp,
+			Value:     stddev.Mul(annualize),
+		})
+	}
+	return points, nil
+}
+
+func checkPeriod(period, length int) error {
+	if period <= 0 {
+		return NewValidationError("Invalid period", "period must be greater than zero")
+	}
+	if length < period {
+		return fmt.Errorf("not enough stocks for period %d. stocks: %d", period, length)
+	}
+	return nil
+}
+
+func closesOf(stocks []Stock) []decimal.Decimal {
+	closes := make([]decimal.Decimal, 0, len(stocks))
+	for _, stock := range stocks {
+		closes = append(closes, decimal.NewFromFloat(stock.Close))
+	}
+	return closes
+}
+
+// ema returns the exponential moving average of values starting at the
+// period-th value.
+func ema(values []decimal.Decimal, period int) []decimal.Decimal {
+	if len(values) < period {
+		return nil
+	}
+	alpha := decimal.NewFromInt(2).Div(decimal.NewFromInt(int64(period + 1)))
+	current := decimal.Avg(values[0], values[1:period]...)
+	result := make([]decimal.Decimal, 0, len(values)-period+1)
+	result = append(result, current)
+	for _, value := range values[period:] {
+		current = value.Sub(current).Mul(alpha).Add(current)
+		result = append(result, current)
+	}
+	return result
+}
+
+func meanStdDev(values []decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	mean := decimal.Avg(values[0], values[1:]...)
+	variance := decimal.Zero
+	for _, value := range values {
+		diff := value.Sub(mean)
+		variance = variance.Add(diff.Mul(diff))
+	}
+	stddev, err := sqrt(variance.Div(decimal.NewFromInt(int64(len(values)))))
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, err
+	}
+	return mean, stddev, nil
+}
+
+func sqrt(d decimal.Decimal) (decimal.Decimal, error) {
+	if d.IsZero() {
+		return decimal.Zero, nil
+	}
+	return d.PowWithPrecision(decimal.NewFromFloat(0.5), int32(decimal.DivisionPrecision))
+}
+
+type IndicatorService struct {
+	stockRepository *StockRepository
+}
+
+func NewIndicatorService(stockRepository *StockRepository) *IndicatorService {
+	return &IndicatorService{
+		stockRepository: stockRepository,
+	}
+}
+
+// Calculate returns the indicator of symbol between start and end. The
+// stocks before start that the indicator needs to warm up are loaded as well.
+func (s *IndicatorService) Calculate(
+	ctx context.Context, symbol string, kind IndicatorKind,
+	params IndicatorParams, start, end time.Time,
+) ([]IndicatorPoint, error) {
+	params = params.WithDefaults(kind)
+	begin := lookbackSince(start, params.Lookback(kind))
+	stocks, err := s.stockRepository.GetStockByPeriod(ctx, symbol, begin, end)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := NewStocks(SymbolDetail{Symbol: symbol}, stocks)
+	if err != nil {
+		return nil, err
+	}
+	points, err := daily.Indicator(kind, params)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]IndicatorPoint, 0, len(points))
+	for _, point := range points {
+		if !point.Timestamp.Before(start.Truncate(24 * time.Hour)) {
+			result = append(result, point)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package notifystock_test
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func indicatorValues(points []notify.IndicatorPoint) []float64 {
+	values := make([]float64, 0, len(points))
+	for _, point := range points {
+		values = append(values, point.Value.InexactFloat64())
+	}
+	return values
+}
+
+func TestStocksIndicator(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   notify.IndicatorKind
+		params notify.IndicatorParams
+		closes []float64
+		want   []float64
+	}{
+		{"sma", notify.IndicatorSMA, notify.IndicatorParams{Period: 3}, []float64{1, 2, 3, 4, 5}, []float64{2, 3, 4}},
+		{"ema", notify.IndicatorEMA, notify.IndicatorParams{Period: 3}, []float64{1, 2, 3, 4, 5}, []float64{2, 3, 4}},
+		{"rsi", notify.IndicatorRSI, notify.IndicatorParams{Period: 2}, []float64{1, 2, 3, 2}, []float64{100, 50}},
+		{"macd", notify.IndicatorMACD, notify.IndicatorParams{FastPeriod: 1, SlowPeriod: 2, SignalPeriod: 1}, []float64{1, 2, 3}, []float64{0.5, 0.5}},
+		{"bollinger", notify.IndicatorBollinger, notify.IndicatorParams{Period: 2}, []float64{1, 3}, []float64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(tt.closes...))
+			assert.NoError(t, err)
+
+			points, err := s.Indicator(tt.kind, tt.params)
+
+			assert.NoError(t, err)
+			assert.InDeltaSlice(t, tt.want, indicatorValues(points), 1e-9)
+		})
+	}
+
+	t.Run("bollinger bands", func(t *testing.T) {
+		s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(1, 3))
+		assert.NoError(t, err)
+
+		points, err := s.BollingerBands(2, decimal.NewFromInt(2))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "4", points[0].Upper.Decimal.String())
+		assert.Equal(t, "0", points[0].Lower.Decimal.String())
+	})
+
+	t.Run("macd histogram", func(t *testing.T) {
+		stocks := newDailyStocks(1, 2, 3)
+		s, err := notify.NewStocks(notify.SymbolDetail{}, stocks)
+		assert.NoError(t, err)
+
+		points, err := s.MACD(1, 2, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, stocks[1].Timestamp, points[0].Timestamp)
+		assert.True(t, points[1].Histogram.Valid)
+		assert.Equal(t, "0", points[1].Histogram.Decimal.String())
+	})
+
+	t.Run("atr", func(t *testing.T) {
+		stocks := newDailyStocks(9, 10, 11, 12)
+		for i, hl := range [][2]float64{{10, 8}, {11, 9}, {12, 10}, {14, 10}} {
+			stocks[i].High, stocks[i].Low = hl[0], hl[1]
+		}
+		s, err := notify.NewStocks(notify.SymbolDetail{}, stocks)
+		assert.NoError(t, err)
+
+		points, err := s.ATR(2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{2, 3}, indicatorValues(points))
+	})
+
+	t.Run("volatility", func(t *testing.T) {
+		s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(100, 110, 99))
+		assert.NoError(t, err)
+
+		points, err := s.Volatility(2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(points))
+		assert.InDelta(t, 1.5874507866, points[0].Value.InexactFloat64(), 1e-9)
+	})
+
+	t.Run("not enough stocks", func(t *testing.T) {
+		s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(1, 2))
+		assert.NoError(t, err)
+
+		_, err = s.RSI(2)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("reject zero period", func(t *testing.T) {
+		s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(1, 2))
+		assert.NoError(t, err)
+
+		_, err = s.ATR(-1)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,222 @@
+package notifystock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Interval string
+
+const (
+	IntervalOneMinute   Interval = "one_minute"
+	IntervalFiveMinutes Interval = "five_minutes"
+	IntervalOneHour     Interval = "one_hour"
+	IntervalOneDay      Interval = "one_day"
+)
+
+// IntradayIntervals are the intervals stored in the intraday table.
+var IntradayIntervals = []Interval{IntervalOneMinute, IntervalFiveMinutes, IntervalOneHour}
+
+func (i Interval) IsValid() bool {
+	switch i {
+	case IntervalOneMinute, IntervalFiveMinutes, IntervalOneHour, IntervalOneDay:
+		return true
+	}
+	return false
+}
+
+func (i Interval) IsIntraday() bool {
+	return i.IsValid() && i != IntervalOneDay
+}
+
+// Param returns the interval parameter of the chart API.
+func (i Interval) Param() string {
+	switch i {
+	case IntervalOneMinute:
+		return "1m"
+	case IntervalFiveMinutes:
+		return "5m"
+	case IntervalOneHour:
+		return "1h"
+	}
+	return "1d"
+}
+
+// Retention returns how long the bars of the interval are kept. It follows
+// how far back the chart API serves each interval.
+func (i Interval) Retention() time.Duration {
+	switch i {
+	case IntervalOneMinute:
+		return 7 * 24 * time.Hour
+	case IntervalFiveMinutes:
+		return 60 * 24 * time.Hour
+	case IntervalOneHour:
+		return 730 * 24 * time.Hour
+	}
+	return 0
+}
+
+// Lookback returns the period fetched on every ingestion of the interval.
+func (i Interval) Lookback() time.Duration {
+	switch i {
+	case IntervalOneMinute, IntervalFiveMinutes:
+		return 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+func NewIntradayStock(symbol string, interval Interval, timestamp time.Time,
+	open, close, high, low float64) (IntradayStock, error) {
+	if !interval.IsIntraday() {
+		return IntradayStock{}, fmt.Errorf("%q is not an intraday interval", interval)
+	}
+	for _, v := range []float64{open, close, high, low} {
+		if v <= 0 {
+			return IntradayStock{}, fmt.Errorf(
+				"value is higher than zero. open: %v, close: %v, high: %v, low: %v, timestamp: %v, symbol: %v",
+				open, close, high, low, timestamp, symbol)
+		}
+	}
+	return IntradayStock{
+		Symbol:    symbol,
+		Interval:  interval,
+		Timestamp: timestamp.UTC(),
+		Open:      open,
+		Close:     close,
+		High:      high,
+		Low:       low,
+	}, nil
+}
+
+// IntradayStock is a bar shorter than a day. Unlike Stock, its timestamp
+// is kept as is.
+type IntradayStock struct {
+	bun.BaseModel `bun:"table:intraday_stocks"`
+
+	Symbol    string    `bun:"symbol,type:text,pk"`
+	Interval  Interval  `bun:"bar_interval,type:text,pk"`
+	Timestamp time.Time `bun:"timestamp,type:timestamp,pk"`
+	Open      float64   `bun:"open,type:decimal,notnull"`
+	Close     float64   `bun:"close,type:decimal,notnull"`
+	High      float64   `bun:"high,type:decimal,notnull"`
+	Low       float64   `bun:"low,type:decimal,notnull"`
+}
+
+type IntradayRepository struct {
+	db *bun.DB
+}
+
+func NewIntradayRepository(db *bun.DB) *IntradayRepository {
+	return &IntradayRepository{
+		db: db,
+	}
+}
+
+func (r *IntradayRepository) Save(ctx context.Context, stocks []IntradayStock) error {
+	if len(stocks) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&stocks).
+		On("CONFLICT (symbol, bar_interval, timestamp) DO UPDATE").
+		Set(strings.Join([]string{
+			"open = EXCLUDED.open",
+			"close = EXCLUDED.close",
+			"high = EXCLUDED.high",
+			"low = EXCLUDED.low",
+		}, ",")).
+		Exec(ctx)
+	return err
+}
+
+func (r *IntradayRepository) GetByPeriod(
+	ctx context.Context, symbol string, interval Interval, start, end time.Time) (
+	[]IntradayStock, error) {
+	var stocks []IntradayStock
+	if err := r.db.NewSelect().
+		Model(&stocks).
+		Where("symbol = ?", symbol).
+		Where("bar_interval = ?", interval).
+		Where("timestamp BETWEEN ? AND ?", start.UTC(), end.UTC()).
+		Order("timestamp ASC").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// Prune deletes the bars of the interval older than before and returns how
+// many were deleted.
+func (r *IntradayRepository) Prune(ctx context.Context, interval Interval, before time.Time) (int64, error) {
+	res, err := r.db.NewDelete().
+		Model((*IntradayStock)(nil)).
+		Where("bar_interval = ?", interval).
+		Where("timestamp < ?", before.UTC()).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+type IntradayProvider interface {
+	FetchIntraday(
+		ctx context.Context, symbol string, interval Interval, start, end time.Time,
+	) ([]IntradayStock, error)
+}
+
+// NewIntradayProvider returns the provider of intraday bars. Only Yahoo
+// Finance serves them.
+func NewIntradayProvider(client HTTPClientInterface) IntradayProvider {
+	return NewFinanceClient(client, Cfg.YahooBaseURL)
+}
+
+type IntradayRegister struct {
+	provider   IntradayProvider
+	repository *IntradayRepository
+}
+
+func NewIntradayRegister(provider IntradayProvider, repository *IntradayRepository) *IntradayRegister {
+	return &IntradayRegister{
+		provider:   provider,
+		repository: repository,
+	}
+}
+
+// Register ingests the bars of the interval over its lookback until now.
+// The returned error joins a SymbolError for every symbol that failed.
+func (r *IntradayRegister) Register(
+	ctx context.Context, symbols []string, interval Interval, now time.Time) error {
+	if !interval.IsIntraday() {
+		return NewValidationError("invalid interval", fmt.Sprintf("%q is not an intraday interval", interval))
+	}
+	var errs []error
+	for _, symbol := range symbols {
+		stocks, err := r.provider.FetchIntraday(ctx, symbol, interval, now.Add(-interval.Lookback()), now)
+		if err == nil {
+			err = r.repository.Save(ctx, stocks)
+		}
+		if err != nil {
+			errs = append(errs, &SymbolError{Symbol: symbol, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Prune deletes the bars of every intraday interval past its retention.
+func (r *IntradayRegister) Prune(ctx context.Context, now time.Time) (int64, error) {
+	var total int64
+	for _, interval := range IntradayIntervals {
+		deleted, err := r.repository.Prune(ctx, interval, now.Add(-interval.Retention()))
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+	return total, nil
+}
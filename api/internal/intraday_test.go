@@ -0,0 +1,91 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestFetchIntraday(t *testing.T) {
+	ctx := context.Background()
+	client := &fixtureClient{fixtures: map[string]string{
+		yahooHost: "testdata/yahoo/chart_N225_5m.json",
+	}}
+	end := time.Unix(1704781800, 0)
+
+	stocks, err := notify.NewFinanceClient(client, notify.YahooFinanceBaseURL).
+		FetchIntraday(ctx, "^N225", notify.IntervalFiveMinutes, end.Add(-24*time.Hour), end)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "5m", client.requests[0].URL.Query().Get("interval"))
+	// the bar without trades is skipped
+	assert.Len(t, stocks, 3)
+	assert.Equal(t, notify.IntradayStock{
+		Symbol:    "^N225",
+		Interval:  notify.IntervalFiveMinutes,
+		Timestamp: time.Date(2024, 1, 9, 0, 35, 0, 0, time.UTC),
+		Open:      33560.12,
+		Close:     33601.55,
+		High:      33610.40,
+		Low:       33551.37,
+	}, stocks[1])
+	assert.Equal(t, time.Date(2024, 1, 9, 0, 45, 0, 0, time.UTC), stocks[2].Timestamp)
+}
+
+func TestInterval(t *testing.T) {
+	assert.Equal(t, "1m", notify.IntervalOneMinute.Param())
+	assert.Equal(t, "1h", notify.IntervalOneHour.Param())
+	assert.True(t, notify.IntervalFiveMinutes.IsIntraday())
+	assert.False(t, notify.IntervalOneDay.IsIntraday())
+	assert.False(t, notify.Interval("2m").IsValid())
+
+	_, err := notify.NewIntradayStock("^N225", notify.IntervalOneDay, time.Now(), 1, 1, 1, 1)
+	assert.Error(t, err)
+}
+
+func TestIntradayRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := notify.NewIntradayRepository(db)
+	now := time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC)
+
+	var stocks []notify.IntradayStock
+	for _, bar := range []struct {
+		interval notify.Interval
+		ago      time.Duration
+	}{
+		{notify.IntervalOneMinute, time.Minute},
+		{notify.IntervalOneMinute, 8 * 24 * time.Hour},
+		{notify.IntervalFiveMinutes, 5 * time.Minute},
+		{notify.IntervalFiveMinutes, 30 * 24 * time.Hour},
+	} {
+		stock, err := notify.NewIntradayStock("^N225", bar.interval, now.Add(-bar.ago), 1, 2, 3, 1)
+		assert.NoError(t, err)
+		stocks = append(stocks, stock)
+	}
+	assert.NoError(t, repo.Save(ctx, stocks))
+
+	t.Run("get by period", func(t *testing.T) {
+		result, err := repo.GetByPeriod(ctx, "^N225", notify.IntervalFiveMinutes, now.Add(-time.Hour), now)
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, now.Add(-5*time.Minute), result[0].Timestamp)
+	})
+
+	t.Run("prune past the retention", func(t *testing.T) {
+		register := notify.NewIntradayRegister(nil, repo)
+
+		deleted, err := register.Prune(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		result, err := repo.GetByPeriod(ctx, "^N225", notify.IntervalOneMinute, now.AddDate(0, 0, -30), now)
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+}
@@ -0,0 +1,116 @@
+package notifystock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Locale string
+
+const (
+	LocaleJA Locale = "ja"
+	LocaleEN Locale = "en"
+
+	DefaultLocale = LocaleJA
+)
+
+func (l Locale) IsValid() bool {
+	_, ok := catalog[l]
+	return ok
+}
+
+// catalog holds the fmt formats of every message per locale.
+var catalog = map[Locale]map[string]string{
+	LocaleEN: {
+		"lang":                         "en",
+		"summary.subject":              "Market Summary %s",
+		"summary.close":                "Closing Price: %s",
+		"summary.moving_average":       "%s Moving Average: %s",
+		"summary.ratio":                "Closing Price to %s Moving Average Ratio: %s",
+		"summary.table.close":          "Close",
+		"summary.table.change":         "Change",
+		"summary.table.moving_average": "%s Moving Average",
+		"summary.table.ratio":          "Closing Price to %s Moving Average Ratio",
+		"window.days":                  "%d-Day",
+		"window.months":                "%d-Month",
+		"window.year":                  "1-Year",
+		"date":                         "January 02 2006",
+		"currency.unknown":             "%s",
+	},
+	LocaleJA: {
+		"lang":                         "ja",
+		"summary.subject":              "マーケットサマリー %s",
+		"summary.close":                "終値: %s",
+		"summary.moving_average":       "%s移動平均: %s",
+		"summary.ratio":                "終値の%s移動平均比: %s",
+		"summary.table.close":          "終値",
+		"summary.table.change":         "前日比",
+		"summary.table.moving_average": "%s移動平均",
+		"summary.table.ratio":          "終値の%s移動平均比",
+		"window.days":                  "%d日",
+		"window.months":                "%dヶ月",
+		"window.year":                  "1年",
+		"date":                         "2006年1月2日",
+		"currency.JPY":                 "%s円",
+		"currency.USD":                 "%sドル",
+		"currency.EUR":                 "%sユーロ",
+		"currency.GBP":                 "%sポンド",
+		"currency.HKD":                 "%s香港ドル",
+		"currency.CNY":                 "%s人民元",
+		"currency.KRW":                 "%sウォン",
+		"currency.unknown":             "%s",
+	},
+}
+
+// T returns the message of key formatted with args. Keys missing in the
+// locale fall back to English.
+func (l Locale) T(key string, args ...any) string {
+	format, ok := catalog[l][key]
+	if !ok {
+		format = catalog[LocaleEN][key]
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// FormatDate formats t as a date, e.g. "October 18 2026" or "2026年10月18日".
+func (l Locale) FormatDate(t time.Time) string {
+	return t.Format(l.T("date"))
+}
+
+// FormatPrice formats the price with thousands separators and the decimal
+// digits of the currency, e.g. "¥40,123" or "40,123円". Currencies without a
+// name in the locale are written with their sign or code.
+func (l Locale) FormatPrice(price decimal.Decimal, currency *Currency) string {
+	if currency == nil || !currency.IsValid() {
+		return l.T("currency.unknown", FormatNumber(price, 2))
+	}
+	amount := FormatNumber(price, currency.MinorUnits())
+	if format, ok := catalog[l]["currency."+currency.String()]; ok {
+		return fmt.Sprintf(format, amount)
+	}
+	return currency.Format(amount)
+}
+
+// FormatNumber rounds d to places and groups the integer digits by thousands.
+func FormatNumber(d decimal.Decimal, places int32) string {
+	s := d.StringFixed(places)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString("." + fraction)
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,49 @@
+package notifystock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestLocaleFormatPrice(t *testing.T) {
+	jpy, usd, gbp, kwd := notify.JPY, notify.USD, notify.GBP, notify.Currency("KWD")
+	tests := []struct {
+		name     string
+		locale   notify.Locale
+		price    decimal.Decimal
+		currency *notify.Currency
+		want     string
+	}{
+		{"yen in japanese", notify.LocaleJA, decimal.NewFromFloat(40123.4), &jpy, "40,123円"},
+		{"yen in english", notify.LocaleEN, decimal.NewFromFloat(40123.4), &jpy, "¥40,123"},
+		{"dollar in english", notify.LocaleEN, decimal.NewFromFloat(6000.125), &usd, "$6,000.13"},
+		{"dollar in japanese", notify.LocaleJA, decimal.NewFromInt(1234567), &usd, "1,234,567.00ドル"},
+		{"pound in english", notify.LocaleEN, decimal.NewFromFloat(1234.5), &gbp, "£1,234.50"},
+		{"pound in japanese", notify.LocaleJA, decimal.NewFromFloat(1234.5), &gbp, "1,234.50ポンド"},
+		{"three minor units", notify.LocaleJA, decimal.NewFromFloat(0.3075), &kwd, "KWD 0.308"},
+		{"without currency", notify.LocaleEN, decimal.NewFromInt(-1000), nil, "-1,000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.locale.FormatPrice(tt.price, tt.currency))
+		})
+	}
+}
+
+func TestLocaleFormatDate(t *testing.T) {
+	date := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2026年10月18日", notify.LocaleJA.FormatDate(date))
+	assert.Equal(t, "October 18 2026", notify.LocaleEN.FormatDate(date))
+}
+
+func TestLocaleT(t *testing.T) {
+	assert.Equal(t, "マーケットサマリー 2026年10月18日", notify.LocaleJA.T("summary.subject", "2026年10月18日"))
+	assert.Equal(t, "Market Summary today", notify.Locale("fr").T("summary.subject", "today"))
+	assert.False(t, notify.Locale("fr").IsValid())
+}
@@ -0,0 +1,53 @@
+package notifystock
+
+import (
+	"time"
+)
+
+// TradingCalendar tells the days an exchange is open.
+type TradingCalendar interface {
+	IsTradingDay(date time.Time) bool
+}
+
+// WeekdayCalendar treats every weekday as a trading day.
+type WeekdayCalendar struct{}
+
+func (WeekdayCalendar) IsTradingDay(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
+
+// TradingDaysAgo returns the day days trading days before the day of now, as
+// midnight in UTC.
+func TradingDaysAgo(calendar TradingCalendar, now time.Time, days int) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for days > 0 {
+		day = day.AddDate(0, 0, -1)
+		if calendar.IsTradingDay(day) {
+			days--
+		}
+	}
+	return day
+}
+
+// TradedSince reports whether a session of the exchange of any of the
+// symbols closed after since, up to now. A symbol on an unknown exchange is
+// taken as traded, and so is one whose calendar does not cover now.
+func TradedSince(details []SymbolDetail, since, now time.Time) bool {
+	for _, detail := range details {
+		c, ok := detail.ExchangeCalendar()
+		if !ok {
+			return true
+		}
+		if !c.Covers(now) {
+			from, to := c.Coverage()
+			logger.Warn("calendar does not cover the day, taking the symbol as traded",
+				"symbol", detail.Symbol, "exchange", c.Exchange(), "date", now, "from", from, "to", to)
+			return true
+		}
+		if c.LastClose(now).After(since) {
+			return true
+		}
+	}
+	return false
+}
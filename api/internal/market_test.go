@@ -0,0 +1,75 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/heyjun3/notify-stock/internal/calendar"
+)
+
+func TestPreviousSessionClose(t *testing.T) {
+	client := &fixtureClient{fixtures: map[string]string{
+		yahooHost: "testdata/yahoo/chart_AAPL_events.json",
+	}}
+
+	stocks, err := notify.NewFinanceClient(client, notify.YahooFinanceBaseURL).FetchStock(
+		context.Background(), "AAPL",
+		time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	symbol := stocks.Symbol()
+	assert.Equal(t, calendar.NASDAQ, symbol.Exchange)
+	// the raw close of Aug 31, not the one adjusted for the dividend
+	assert.Equal(t, decimal.RequireFromString("129.04"), symbol.PreviousClose)
+}
+
+func TestWeekdayCalendar(t *testing.T) {
+	weekdays := notify.WeekdayCalendar{}
+
+	assert.True(t, weekdays.IsTradingDay(day(time.January, 5)))
+	assert.False(t, weekdays.IsTradingDay(day(time.January, 6)))
+	assert.False(t, weekdays.IsTradingDay(day(time.January, 7)))
+	assert.True(t, weekdays.IsTradingDay(day(time.January, 8)))
+}
+
+func TestTradingDaysAgo(t *testing.T) {
+	tse, err := calendar.For(calendar.TSE)
+	assert.NoError(t, err)
+	// a Tuesday after Golden Week
+	now := time.Date(2024, 5, 7, 6, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC), notify.TradingDaysAgo(notify.WeekdayCalendar{}, now, 5))
+	assert.Equal(t, time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC), notify.TradingDaysAgo(tse, now, 5))
+}
+
+func TestTradedSince(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	n225 := notify.SymbolDetail{Symbol: "^N225", Exchange: calendar.TSE}
+	gspc := notify.SymbolDetail{Symbol: "^GSPC", Exchange: calendar.NYSE}
+	unknown := notify.SymbolDetail{Symbol: "XXX"}
+
+	tests := []struct {
+		name    string
+		details []notify.SymbolDetail
+		now     time.Time
+		want    bool
+	}{
+		{"weekday", []notify.SymbolDetail{n225}, time.Date(2024, 1, 10, 20, 0, 0, 0, jst), true},
+		{"sunday", []notify.SymbolDetail{n225, gspc}, time.Date(2024, 1, 7, 20, 0, 0, 0, jst), false},
+		// the New York session of Friday closes on Saturday morning in Tokyo
+		{"saturday", []notify.SymbolDetail{n225, gspc}, time.Date(2024, 1, 6, 20, 0, 0, 0, jst), true},
+		{"holiday", []notify.SymbolDetail{n225}, time.Date(2024, 1, 8, 20, 0, 0, 0, jst), false},
+		{"unknown exchange", []notify.SymbolDetail{unknown}, time.Date(2024, 1, 7, 20, 0, 0, 0, jst), true},
+		{"not covered by the calendar", []notify.SymbolDetail{n225}, time.Date(2028, 1, 9, 20, 0, 0, 0, jst), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, notify.TradedSince(tt.details, tt.now.Add(-24*time.Hour), tt.now))
+		})
+	}
+}
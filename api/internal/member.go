@@ -14,7 +14,11 @@ import (
 type Member struct {
 	bun.BaseModel `bun:"table:members"`
 
-	ID uuid.UUID `bun:"id,type:uuid,pk"`
+	ID     uuid.UUID `bun:"id,type:uuid,pk"`
+	Locale Locale    `bun:"locale,type:text,notnull,default:'ja'"`
+	// IsAdmin allows to manage the tracked symbols. It is only set in the
+	// database.
+	IsAdmin bool `bun:"is_admin,notnull,default:false"`
 
 	GoogleMember *GoogleMember `bun:"rel:has-one,join:id=member_id"`
 }
@@ -28,10 +32,18 @@ func NewMember(id *uuid.UUID) (*Member, error) {
 		id = &i
 	}
 	return &Member{
-		ID: *id,
+		ID:     *id,
+		Locale: DefaultLocale,
 	}, nil
 }
 
+func (m *Member) Email() string {
+	if m.GoogleMember == nil {
+		return ""
+	}
+	return m.GoogleMember.Email
+}
+
 type GoogleMember struct {
 	bun.BaseModel `bun:"table:google_members"`
 
@@ -95,6 +107,41 @@ func (r *MemberRepository) GetByID(ctx context.Context, id uuid.UUID) (*Member,
 	return &member, nil
 }
 
+func (r *MemberRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*Member, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var members []*Member
+	if err := r.db.NewSelect().
+		Model(&members).
+		Where("member.id IN (?)", bun.In(ids)).
+		Relation("GoogleMember").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *MemberRepository) UpdateLocale(ctx context.Context, id uuid.UUID, locale Locale) error {
+	if !locale.IsValid() {
+		return NewValidationError("Unsupported locale", fmt.Sprintf("locale %q is not supported", locale))
+	}
+	res, err := r.db.NewUpdate().
+		Model((*Member)(nil)).
+		Set("locale = ?", locale).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return NewNotFoundError("Member")
+	}
+	return nil
+}
+
 func (r *MemberRepository) GetByGoogleID(ctx context.Context, googleID string) (*Member, error) {
 	var member Member
 	if err := r.db.NewSelect().
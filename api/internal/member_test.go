@@ -118,4 +118,21 @@ func TestMemberRepository(t *testing.T) {
 		err = repo.Save(ctx, []*notify.Member{member})
 		assert.NoError(t, err)
 	})
+	t.Run("update locale", func(t *testing.T) {
+		ctx := context.Background()
+		member, err := notify.NewMember(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, notify.LocaleJA, member.Locale)
+		err = repo.Save(ctx, []*notify.Member{member})
+		assert.NoError(t, err)
+
+		err = repo.UpdateLocale(ctx, member.ID, notify.LocaleEN)
+		assert.NoError(t, err)
+		saved, err := repo.GetByID(ctx, member.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, notify.LocaleEN, saved.Locale)
+
+		err = repo.UpdateLocale(ctx, member.ID, notify.Locale("fr"))
+		assert.Error(t, err)
+	})
 }
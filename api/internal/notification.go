@@ -3,6 +3,10 @@ package notifystock
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,11 +17,55 @@ import (
 type Notification struct {
 	bun.BaseModel `bun:"table:notifications"`
 
-	ID       uuid.UUID  `bun:"id,type:uuid,pk,default:gen_random_uuid()"`
-	MemberID uuid.UUID  `bun:"member_id,type:uuid"`
-	Time     TimeOfHour `bun:"embed:"`
+	ID       uuid.UUID           `bun:"id,type:uuid,pk,default:gen_random_uuid()"`
+	MemberID uuid.UUID           `bun:"member_id,type:uuid"`
+	Time     TimeOfHour          `bun:"embed:"`
+	Window   MovingAverageWindow `bun:"moving_average_window,type:text,notnull,default:'months_12'"`
 
-	Targets []*NotificationTarget `bun:"rel:has-many,join:id=notification_id"`
+	Targets  []*NotificationTarget  `bun:"rel:has-many,join:id=notification_id"`
+	Channels []*NotificationChannel `bun:"rel:has-many,join:id=notification_id"`
+}
+
+func (n *Notification) SetWindow(window MovingAverageWindow) error {
+	if !window.IsValid() {
+		return NewValidationError("Unsupported moving average window", fmt.Sprintf("window %q is not supported", window))
+	}
+	n.Window = window
+	return nil
+}
+
+// Symbols returns the symbols of the targets.
+func (n *Notification) Symbols() []string {
+	symbols := make([]string, 0, len(n.Targets))
+	for _, target := range n.Targets {
+		symbols = append(symbols, target.Symbol)
+	}
+	return symbols
+}
+
+type NotificationChannelSetting struct {
+	Kind        DeliveryChannel
+	Destination string
+}
+
+// SetChannels replaces the channels the notification is delivered to.
+// Without any channel the notification is mailed to its member.
+func (n *Notification) SetChannels(settings []NotificationChannelSetting) error {
+	channels := make([]*NotificationChannel, 0, len(settings))
+	seen := make(map[DeliveryChannel]struct{}, len(settings))
+	for _, setting := range settings {
+		if _, ok := seen[setting.Kind]; ok {
+			return NewValidationError("Duplicate channel", fmt.Sprintf("channel %s is set more than once", setting.Kind))
+		}
+		seen[setting.Kind] = struct{}{}
+		channel, err := NewNotificationChannel(nil, n.ID, setting.Kind, setting.Destination)
+		if err != nil {
+			return err
+		}
+		channels = append(channels, channel)
+	}
+	n.Channels = channels
+	return nil
 }
 
 type TimeOfHour struct {
@@ -51,6 +99,7 @@ func NewNotification(ID *uuid.UUID, memberID uuid.UUID, symbols []string, hour t
 		ID:       *ID,
 		MemberID: memberID,
 		Time:     NewTimeOfHour(hour),
+		Window:   DefaultMovingAverageWindow,
 		Targets:  targets,
 	}, nil
 }
@@ -78,6 +127,97 @@ func NewNotificationTarget(ID *uuid.UUID, notificationID uuid.UUID, symbol strin
 	}, nil
 }
 
+type NotificationChannel struct {
+	bun.BaseModel `bun:"table:notification_channels"`
+
+	ID             uuid.UUID       `bun:"id,type:uuid,pk"`
+	NotificationID uuid.UUID       `bun:"notification_id,type:uuid,notnull"`
+	Kind           DeliveryChannel `bun:"kind,type:text,notnull"`
+	Destination    string          `bun:"destination,type:text,notnull"`
+}
+
+// NewNotificationChannel validates the destination of the channel. An email
+// channel is sent to the member's address.
+func NewNotificationChannel(
+	ID *uuid.UUID, notificationID uuid.UUID, kind DeliveryChannel, destination string,
+) (*NotificationChannel, error) {
+	if !kind.IsValid() {
+		return nil, NewValidationError("Unsupported channel", fmt.Sprintf("channel %q is not supported", kind))
+	}
+	if err := validateDestination(kind, destination); err != nil {
+		return nil, err
+	}
+	if ID == nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		ID = &id
+	}
+	return &NotificationChannel{
+		ID:             *ID,
+		NotificationID: notificationID,
+		Kind:           kind,
+		Destination:    destination,
+	}, nil
+}
+
+// channelHosts are the only hosts the chat channels post to.
+var channelHosts = map[DeliveryChannel]string{
+	DeliveryChannelSlack:   "hooks.slack.com",
+	DeliveryChannelDiscord: "discord.com",
+}
+
+// validateDestination checks that email destinations are addresses, that
+// chat webhooks belong to their service and that other webhooks are signed
+// and go to public hosts.
+func validateDestination(kind DeliveryChannel, destination string) error {
+	if kind == DeliveryChannelEmail {
+		if destination == "" {
+			return nil
+		}
+		if address, err := mail.ParseAddress(destination); err != nil || address.Address != destination {
+			return NewValidationError("Invalid email address", fmt.Sprintf("%q is not an email address", destination))
+		}
+		return nil
+	}
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return NewValidationError("Invalid webhook URL", fmt.Sprintf("%s channel requires an https URL", kind))
+	}
+	if host, ok := channelHosts[kind]; ok {
+		if u.Hostname() != host {
+			return NewValidationError("Invalid webhook URL", fmt.Sprintf("%s channel requires a URL of %s", kind, host))
+		}
+		return nil
+	}
+	if Cfg.WebhookSecret == "" {
+		return NewValidationError("Unsupported channel", fmt.Sprintf("%s channel requires a webhook secret", kind))
+	}
+	if !isPublicHost(u.Hostname()) {
+		return NewValidationError("Invalid webhook URL", fmt.Sprintf("host %q is not public", u.Hostname()))
+	}
+	return nil
+}
+
+// isPublicHost rejects the addresses and names of local networks. Names that
+// resolve to them are refused on connection by NewChannelHTTPClient.
+func isPublicHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return IsPublicIP(ip)
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if !strings.Contains(host, ".") {
+		return false
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal"} {
+		if strings.HasSuffix(host, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
 type NotificationRepository struct {
 	db *bun.DB
 }
@@ -88,22 +228,41 @@ func NewNotificationRepository(db *bun.DB) *NotificationRepository {
 	}
 }
 
+// Save upserts the notifications and replaces their targets and channels,
+// keeping the IDs of the notifications so that their deliveries are kept.
 func (r *NotificationRepository) Save(ctx context.Context, n []Notification) error {
 	if len(n) == 0 {
 		return nil
 	}
 	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
-		_, err := r.db.NewInsert().
+		_, err := tx.NewInsert().
 			Model(&n).
 			On("CONFLICT (id) DO UPDATE").
 			Set(strings.Join([]string{
 				"member_id = EXCLUDED.member_id",
 				"hour = EXCLUDED.hour",
+				"moving_average_window = EXCLUDED.moving_average_window",
 			}, ",")).
 			Exec(ctx)
 		if err != nil {
 			return err
 		}
+		ids := make([]uuid.UUID, 0, len(n))
+		for _, notification := range n {
+			ids = append(ids, notification.ID)
+		}
+		if _, err := tx.NewDelete().
+			Model((*NotificationTarget)(nil)).
+			Where("notification_id IN (?)", bun.In(ids)).
+			Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := tx.NewDelete().
+			Model((*NotificationChannel)(nil)).
+			Where("notification_id IN (?)", bun.In(ids)).
+			Exec(ctx); err != nil {
+			return err
+		}
 		targets := make([]*NotificationTarget, 0, len(n))
 		for _, notification := range n {
 			targets = append(targets, notification.Targets...)
@@ -111,7 +270,7 @@ func (r *NotificationRepository) Save(ctx context.Context, n []Notification) err
 		if len(targets) == 0 {
 			return nil
 		}
-		_, err = r.db.NewInsert().
+		_, err = tx.NewInsert().
 			Model(&targets).
 			On("CONFLICT (id) DO UPDATE").
 			Set(strings.Join([]string{
@@ -119,6 +278,25 @@ func (r *NotificationRepository) Save(ctx context.Context, n []Notification) err
 				"symbol = EXCLUDED.symbol",
 			}, ",")).
 			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		channels := make([]*NotificationChannel, 0, len(n))
+		for _, notification := range n {
+			channels = append(channels, notification.Channels...)
+		}
+		if len(channels) == 0 {
+			return nil
+		}
+		_, err = tx.NewInsert().
+			Model(&channels).
+			On("CONFLICT (id) DO UPDATE").
+			Set(strings.Join([]string{
+				"notification_id = EXCLUDED.notification_id",
+				"kind = EXCLUDED.kind",
+				"destination = EXCLUDED.destination",
+			}, ",")).
+			Exec(ctx)
 		return err
 	})
 	return err
@@ -130,6 +308,7 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*No
 		Model(&n).
 		Where("id = ?", id).
 		Relation("Targets").
+		Relation("Channels").
 		Scan(ctx)
 	if err != nil {
 		return nil, err
@@ -143,6 +322,7 @@ func (r *NotificationRepository) GetByMemberID(ctx context.Context, memberID uui
 		Model(&n).
 		Where("member_id = ?", memberID).
 		Relation("Targets").
+		Relation("Channels").
 		Scan(ctx)
 	if err != nil {
 		return nil, err
@@ -155,6 +335,7 @@ func (r *NotificationRepository) GetByHour(ctx context.Context, time TimeOfHour)
 	err := r.db.NewSelect().
 		Model(&n).
 		Relation("Targets").
+		Relation("Channels").
 		Where("hour = ?", time.Hour.Format("15:04:05")).
 		Scan(ctx)
 	if err != nil {
@@ -174,22 +355,29 @@ func (r *NotificationRepository) DeleteByMemberID(ctx context.Context, memberID
 }
 
 type NotificationCreator struct {
-	notificationRepository *NotificationRepository
-	symbolRepository       *SymbolRepository
+	notificationRepository  *NotificationRepository
+	symbolRepository        *SymbolRepository
+	trackedSymbolRepository *TrackedSymbolRepository
+	memberRepository        *MemberRepository
 }
 
 func NewNotificationCreator(
 	notificationRepository *NotificationRepository,
 	symbolRepository *SymbolRepository,
+	trackedSymbolRepository *TrackedSymbolRepository,
+	memberRepository *MemberRepository,
 ) *NotificationCreator {
 	return &NotificationCreator{
-		notificationRepository: notificationRepository,
-		symbolRepository:       symbolRepository,
+		notificationRepository:  notificationRepository,
+		symbolRepository:        symbolRepository,
+		trackedSymbolRepository: trackedSymbolRepository,
+		memberRepository:        memberRepository,
 	}
 }
 
 func (n *NotificationCreator) Create(
 	ctx context.Context, memberID uuid.UUID, symbols []string, hour time.Time,
+	channels []NotificationChannelSetting, window MovingAverageWindow,
 ) (*Notification, error) {
 	symbolDetails, err := n.symbolRepository.GetBySymbols(ctx, symbols)
 	if err != nil {
@@ -198,15 +386,71 @@ func (n *NotificationCreator) Create(
 	if len(symbolDetails) != len(symbols) {
 		return nil, fmt.Errorf("unsupported symbols: %v", symbols)
 	}
-	notification, err := NewNotification(nil, memberID, symbols, hour)
+	tracked, err := n.trackedSymbolRepository.EnabledSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, symbol := range symbols {
+		if !slices.Contains(tracked, symbol) {
+			return nil, fmt.Errorf("untracked symbol: %v", symbol)
+		}
+	}
+	channels, err = n.ownEmailChannels(ctx, memberID, channels)
 	if err != nil {
 		return nil, err
 	}
-	if _, err := n.notificationRepository.DeleteByMemberID(ctx, memberID); err != nil {
+	// A member has one notification, which is updated in place so that its
+	// deliveries and their idempotency keys are kept.
+	existing, err := n.notificationRepository.GetByMemberID(ctx, memberID)
+	if err != nil {
 		return nil, err
 	}
+	var id *uuid.UUID
+	if len(existing) > 0 {
+		id = &existing[0].ID
+	}
+	notification, err := NewNotification(id, memberID, symbols, hour)
+	if err != nil {
+		return nil, err
+	}
+	if err := notification.SetChannels(channels); err != nil {
+		return nil, err
+	}
+	if window != "" {
+		if err := notification.SetWindow(window); err != nil {
+			return nil, err
+		}
+	}
 	if err := n.notificationRepository.Save(ctx, []Notification{*notification}); err != nil {
 		return nil, err
 	}
 	return notification, nil
 }
+
+// ownEmailChannels rejects email channels to addresses other than the
+// member's own, since mails to them are not confirmed by their owners. The
+// member's address is left out of the settings, which stands for it.
+func (n *NotificationCreator) ownEmailChannels(
+	ctx context.Context, memberID uuid.UUID, channels []NotificationChannelSetting,
+) ([]NotificationChannelSetting, error) {
+	own := make([]NotificationChannelSetting, 0, len(channels))
+	var email string
+	for _, channel := range channels {
+		if channel.Kind == DeliveryChannelEmail && channel.Destination != "" {
+			if email == "" {
+				member, err := n.memberRepository.GetByID(ctx, memberID)
+				if err != nil {
+					return nil, err
+				}
+				email = member.Email()
+			}
+			if !strings.EqualFold(channel.Destination, email) {
+				return nil, NewValidationError("Invalid email address",
+					fmt.Sprintf("%q is not the member's address", channel.Destination))
+			}
+			channel.Destination = ""
+		}
+		own = append(own, channel)
+	}
+	return own, nil
+}
@@ -24,6 +24,51 @@ func createMember(t *testing.T, repo *notify.MemberRepository) *notify.Member {
 	return member
 }
 
+func TestNewNotificationChannel(t *testing.T) {
+	secret := notify.Cfg.WebhookSecret
+	notify.Cfg.WebhookSecret = "secret"
+	t.Cleanup(func() { notify.Cfg.WebhookSecret = secret })
+
+	tests := []struct {
+		name        string
+		kind        notify.DeliveryChannel
+		destination string
+		valid       bool
+	}{
+		{"member's address", notify.DeliveryChannelEmail, "", true},
+		{"email address", notify.DeliveryChannelEmail, "member@example.com", true},
+		{"not an email address", notify.DeliveryChannelEmail, "Member <member@example.com>", false},
+		{"slack", notify.DeliveryChannelSlack, "https://hooks.slack.com/services/T000/B000/XXX", true},
+		{"slack on another host", notify.DeliveryChannelSlack, "https://example.com/services/T000", false},
+		{"discord", notify.DeliveryChannelDiscord, "https://discord.com/api/webhooks/1/a", true},
+		{"discord on another host", notify.DeliveryChannelDiscord, "https://discord.com.example.com/api/webhooks/1/a", false},
+		{"webhook", notify.DeliveryChannelWebhook, "https://example.com/hooks", true},
+		{"webhook over http", notify.DeliveryChannelWebhook, "http://example.com/hooks", false},
+		{"webhook to loopback", notify.DeliveryChannelWebhook, "https://127.0.0.1/hooks", false},
+		{"webhook to metadata", notify.DeliveryChannelWebhook, "https://169.254.169.254/latest", false},
+		{"webhook to localhost", notify.DeliveryChannelWebhook, "https://localhost:8080/hooks", false},
+		{"webhook to internal name", notify.DeliveryChannelWebhook, "https://db.internal/hooks", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := notify.NewNotificationChannel(nil, uuid.New(), tt.kind, tt.destination)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("webhook without secret", func(t *testing.T) {
+		notify.Cfg.WebhookSecret = ""
+
+		_, err := notify.NewNotificationChannel(nil, uuid.New(), notify.DeliveryChannelWebhook, "https://example.com/hooks")
+
+		assert.Error(t, err)
+	})
+}
+
 func TestNotificationRepository(t *testing.T) {
 	ctx := context.Background()
 	db := openDB(t)
@@ -150,6 +195,7 @@ func TestNotificationCreator(t *testing.T) {
 	symbol2 := notify.NewSymbolDetail("TEST2", "test name", "test long", "JPY", decimal.New(1000, 0), decimal.New(10000, 0))
 	err := symbolRepository.Save(ctx, []notify.SymbolDetail{*symbol, *symbol2})
 	assert.NoError(t, err)
+	trackSymbols(t, db, symbol.Symbol, symbol2.Symbol)
 
 	t.Run("create notification", func(t *testing.T) {
 		member, err := notify.NewMember(nil)
@@ -159,7 +205,7 @@ func TestNotificationCreator(t *testing.T) {
 
 		creator := notify.InitNotificationCreator(db)
 
-		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), nil, notify.DefaultMovingAverageWindow)
 		assert.NoError(t, err)
 
 		assert.Equal(t, 12, notification.Time.Hour.Hour())
@@ -174,19 +220,116 @@ func TestNotificationCreator(t *testing.T) {
 
 		creator := notify.InitNotificationCreator(db)
 
-		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), nil, notify.DefaultMovingAverageWindow)
 		assert.NoError(t, err)
 
 		assert.Equal(t, 12, notification.Time.Hour.Hour())
 		assert.Equal(t, symbol.Symbol, notification.Targets[0].Symbol)
 
-		notification, err = creator.Create(ctx, member.ID, []string{symbol2.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+		deliveryRepository := notify.NewNotificationDeliveryRepository(db)
+		delivery, err := notify.NewNotificationDelivery(nil, notification.ID, time.Now(), notify.DeliveryChannelEmail)
+		assert.NoError(t, err)
+		claimed, err := deliveryRepository.Claim(ctx, delivery)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		updated, err := creator.Create(ctx, member.ID, []string{symbol2.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), nil, notify.DefaultMovingAverageWindow)
 		assert.NoError(t, err)
 
 		notifications, err := notificationRepository.GetByMemberID(ctx, member.ID)
 		assert.NoError(t, err)
 
 		assert.Equal(t, 1, len(notifications))
-		assert.Equal(t, symbol2.Symbol, notification.Targets[0].Symbol)
+		assert.Equal(t, notification.ID, updated.ID)
+		assert.Len(t, notifications[0].Targets, 1)
+		assert.Equal(t, symbol2.Symbol, notifications[0].Targets[0].Symbol)
+		// the deliveries of the notification are kept
+		deliveries, err := deliveryRepository.GetByNotificationID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+	})
+
+	t.Run("reject untracked symbol", func(t *testing.T) {
+		member := createMember(t, memberRepository)
+		untracked := notify.NewSymbolDetail("UNTRACKED", "test name", "test long", "JPY", decimal.New(1000, 0), decimal.New(10000, 0))
+		assert.NoError(t, symbolRepository.Save(ctx, []notify.SymbolDetail{*untracked}))
+		creator := notify.InitNotificationCreator(db)
+
+		_, err := creator.Create(ctx, member.ID, []string{symbol.Symbol, untracked.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), nil, notify.DefaultMovingAverageWindow)
+
+		assert.ErrorContains(t, err, "untracked symbol: UNTRACKED")
+	})
+
+	t.Run("create notification with channels", func(t *testing.T) {
+		member := createMember(t, memberRepository)
+		creator := notify.InitNotificationCreator(db)
+
+		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			[]notify.NotificationChannelSetting{
+				{Kind: notify.DeliveryChannelEmail},
+				{Kind: notify.DeliveryChannelSlack, Destination: "https://hooks.slack.com/services/T000/B000/XXX"},
+			}, notify.DefaultMovingAverageWindow)
+		assert.NoError(t, err)
+
+		saved, err := notificationRepository.GetByID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(saved.Channels))
+		kinds := []notify.DeliveryChannel{saved.Channels[0].Kind, saved.Channels[1].Kind}
+		assert.ElementsMatch(t, []notify.DeliveryChannel{notify.DeliveryChannelEmail, notify.DeliveryChannelSlack}, kinds)
+	})
+
+	t.Run("reject invalid channels", func(t *testing.T) {
+		member := createMember(t, memberRepository)
+		creator := notify.InitNotificationCreator(db)
+		hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		_, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, hour,
+			[]notify.NotificationChannelSetting{{Kind: notify.DeliveryChannelWebhook, Destination: "http://example.com"}},
+			notify.DefaultMovingAverageWindow)
+		assert.Error(t, err)
+
+		_, err = creator.Create(ctx, member.ID, []string{symbol.Symbol}, hour,
+			[]notify.NotificationChannelSetting{
+				{Kind: notify.DeliveryChannelDiscord, Destination: "https://discord.com/api/webhooks/1/a"},
+				{Kind: notify.DeliveryChannelDiscord, Destination: "https://discord.com/api/webhooks/2/b"},
+			}, notify.DefaultMovingAverageWindow)
+		assert.Error(t, err)
+	})
+
+	t.Run("email only to the member's address", func(t *testing.T) {
+		member, err := notify.NewGoogleMember(nil, "own@example.com", "own@example.com", true,
+			"Name", "Given", "Family", "Picture")
+		assert.NoError(t, err)
+		assert.NoError(t, memberRepository.Save(ctx, []*notify.Member{member}))
+		creator := notify.InitNotificationCreator(db)
+		hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		_, err = creator.Create(ctx, member.ID, []string{symbol.Symbol}, hour,
+			[]notify.NotificationChannelSetting{{Kind: notify.DeliveryChannelEmail, Destination: "other@example.com"}},
+			notify.DefaultMovingAverageWindow)
+		assert.Error(t, err)
+
+		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, hour,
+			[]notify.NotificationChannelSetting{{Kind: notify.DeliveryChannelEmail, Destination: "own@example.com"}},
+			notify.DefaultMovingAverageWindow)
+		assert.NoError(t, err)
+		assert.Empty(t, notification.Channels[0].Destination)
+	})
+
+	t.Run("create notification with moving average window", func(t *testing.T) {
+		member := createMember(t, memberRepository)
+		creator := notify.InitNotificationCreator(db)
+		hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		notification, err := creator.Create(ctx, member.ID, []string{symbol.Symbol}, hour,
+			nil, notify.MovingAverageWindow75Days)
+		assert.NoError(t, err)
+		saved, err := notificationRepository.GetByID(ctx, notification.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, notify.MovingAverageWindow75Days, saved.Window)
+
+		_, err = creator.Create(ctx, member.ID, []string{symbol.Symbol}, hour,
+			nil, notify.MovingAverageWindow("days_30"))
+		assert.Error(t, err)
 	})
 }
@@ -0,0 +1,292 @@
+package notifystock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusDead    OutboxStatus = "dead"
+)
+
+type OutboxMessage struct {
+	bun.BaseModel `bun:"table:mail_outbox"`
+
+	ID            uuid.UUID       `bun:"id,type:uuid,pk"`
+	DeliveryID    *uuid.UUID      `bun:"delivery_id,type:uuid"`
+	Channel       DeliveryChannel `bun:"channel,type:text,notnull"`
+	From          string          `bun:"from_address,type:text,notnull"`
+	To            string          `bun:"to_address,type:text,notnull"`
+	Subject       string          `bun:"subject,type:text,notnull"`
+	Text          string          `bun:"text,type:text,notnull"`
+	HTML          string          `bun:"html,type:text,nullzero"`
+	Inline        []InlineImage   `bun:"inline_images,type:jsonb,nullzero"`
+	Status        OutboxStatus    `bun:"status,type:text,notnull"`
+	Attempts      int             `bun:"attempts,notnull"`
+	NextAttemptAt time.Time       `bun:"next_attempt_at,type:timestamp,notnull"`
+	LastError     string          `bun:"last_error,type:text,nullzero"`
+	CreatedAt     time.Time       `bun:"created_at,type:timestamp,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time       `bun:"updated_at,type:timestamp,notnull,default:current_timestamp"`
+}
+
+func NewOutboxMessage(
+	ID *uuid.UUID, deliveryID *uuid.UUID, channel DeliveryChannel,
+	from, to, subject, text string,
+) (*OutboxMessage, error) {
+	if ID == nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		ID = &id
+	}
+	now := time.Now()
+	return &OutboxMessage{
+		ID:            *ID,
+		DeliveryID:    deliveryID,
+		Channel:       channel,
+		From:          from,
+		To:            to,
+		Subject:       subject,
+		Text:          text,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+func (m *OutboxMessage) Sent(now time.Time) {
+	m.Status = OutboxStatusSent
+	m.Attempts++
+	m.LastError = ""
+	m.UpdatedAt = now
+}
+
+// Failed records a failed attempt. The message is scheduled for another
+// attempt after the backoff delay, or moved to the dead state once it has
+// been attempted maxAttempts times.
+func (m *OutboxMessage) Failed(err error, now time.Time, backoff Backoff, maxAttempts int) {
+	m.Attempts++
+	m.LastError = err.Error()
+	m.UpdatedAt = now
+	if m.Attempts >= maxAttempts {
+		m.Status = OutboxStatusDead
+		return
+	}
+	m.NextAttemptAt = now.Add(backoff.Delay(m.Attempts))
+}
+
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay returns the wait before the next attempt, doubling Base after every
+// failed attempt up to Max.
+func (b Backoff) Delay(attempts int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= b.Max {
+			return b.Max
+		}
+	}
+	return min(delay, b.Max)
+}
+
+type OutboxRepository struct {
+	db *bun.DB
+}
+
+func NewOutboxRepository(db *bun.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+func (r *OutboxRepository) Save(ctx context.Context, messages []*OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&messages).
+		On("CONFLICT (id) DO NOTHING").
+		Exec(ctx)
+	return err
+}
+
+// Lease returns up to limit pending messages that are due at now and hides
+// them from other workers for the lease duration.
+func (r *OutboxRepository) Lease(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]*OutboxMessage, error) {
+	due := r.db.NewSelect().
+		Model((*OutboxMessage)(nil)).
+		Column("id").
+		Where("status = ?", OutboxStatusPending).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+	var messages []*OutboxMessage
+	_, err := r.db.NewUpdate().
+		Model((*OutboxMessage)(nil)).
+		Set("next_attempt_at = ?", now.Add(lease)).
+		Where("id IN (?)", due).
+		Returning("*").
+		Exec(ctx, &messages)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) Update(ctx context.Context, message *OutboxMessage) error {
+	_, err := r.db.NewUpdate().
+		Model(message).
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+func (r *OutboxRepository) GetByStatus(ctx context.Context, status OutboxStatus) ([]*OutboxMessage, error) {
+	var messages []*OutboxMessage
+	err := r.db.NewSelect().
+		Model(&messages).
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+type OutboxWorkerOption struct {
+	MaxAttempts int
+	Backoff     Backoff
+	BatchSize   int
+	Lease       time.Duration
+}
+
+func DefaultOutboxWorkerOption() OutboxWorkerOption {
+	return OutboxWorkerOption{
+		MaxAttempts: 5,
+		Backoff:     Backoff{Base: time.Minute, Max: time.Hour},
+		BatchSize:   50,
+		Lease:       5 * time.Minute,
+	}
+}
+
+// OutboxWorker drains the outbox through the channel of each message.
+type OutboxWorker struct {
+	channels           Channels
+	outboxRepository   *OutboxRepository
+	deliveryRepository *NotificationDeliveryRepository
+	option             OutboxWorkerOption
+}
+
+func NewOutboxWorker(
+	channels Channels,
+	outboxRepository *OutboxRepository,
+	deliveryRepository *NotificationDeliveryRepository,
+	option OutboxWorkerOption,
+) *OutboxWorker {
+	return &OutboxWorker{
+		channels:           channels,
+		outboxRepository:   outboxRepository,
+		deliveryRepository: deliveryRepository,
+		option:             option,
+	}
+}
+
+// Drain sends every message due at now and returns how many were attempted.
+func (w *OutboxWorker) Drain(ctx context.Context, now time.Time) (int, error) {
+	attempted := 0
+	for {
+		messages, err := w.outboxRepository.Lease(
+			ctx, now, w.option.Lease, w.option.BatchSize,
+		)
+		if err != nil {
+			return attempted, err
+		}
+		if len(messages) == 0 {
+			return attempted, nil
+		}
+		for _, message := range messages {
+			if err := w.send(ctx, message, now); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+	}
+}
+
+func (w *OutboxWorker) send(ctx context.Context, message *OutboxMessage, now time.Time) error {
+	response, err := w.deliver(ctx, message)
+	if err != nil {
+		logger.Warn("failed to send outbox message",
+			"id", message.ID, "attempts", message.Attempts+1, "error", err)
+		message.Failed(err, now, w.option.Backoff, w.option.MaxAttempts)
+	} else {
+		message.Sent(now)
+	}
+	if e := w.outboxRepository.Update(ctx, message); e != nil {
+		return e
+	}
+	if message.DeliveryID == nil {
+		return nil
+	}
+	delivery, e := w.deliveryRepository.GetByID(ctx, *message.DeliveryID)
+	if e != nil {
+		return e
+	}
+	switch {
+	case err == nil:
+		delivery.Sent(response)
+	case message.Status == OutboxStatusDead:
+		delivery.Dead(err)
+	default:
+		delivery.Retrying(err)
+	}
+	return w.deliveryRepository.Update(ctx, delivery)
+}
+
+func (w *OutboxWorker) deliver(ctx context.Context, message *OutboxMessage) (string, error) {
+	channel, ok := w.channels[message.Channel]
+	if !ok {
+		return "", fmt.Errorf("unsupported channel %q", message.Channel)
+	}
+	return channel.Send(ctx, message.To, ChannelMessage{
+		From:    message.From,
+		Subject: message.Subject,
+		Text:    message.Text,
+		HTML:    message.HTML,
+		Inline:  message.Inline,
+	})
+}
+
+// Run drains the outbox every interval until ctx is canceled.
+func (w *OutboxWorker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Drain(ctx, time.Now()); err != nil {
+			logger.Error("failed to drain outbox", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
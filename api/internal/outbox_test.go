@@ -0,0 +1,114 @@
+package notifystock_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := notify.Backoff{Base: time.Minute, Max: 10 * time.Minute}
+
+	tests := []struct {
+		name     string
+		attempts int
+		expected time.Duration
+	}{
+		{name: "first retry", attempts: 1, expected: time.Minute},
+		{name: "second retry", attempts: 2, expected: 2 * time.Minute},
+		{name: "third retry", attempts: 3, expected: 4 * time.Minute},
+		{name: "capped", attempts: 5, expected: 10 * time.Minute},
+		{name: "far beyond the cap", attempts: 100, expected: 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, backoff.Delay(tt.attempts))
+		})
+	}
+}
+
+func TestOutboxMessageFailed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	backoff := notify.Backoff{Base: time.Minute, Max: time.Hour}
+	message, err := notify.NewOutboxMessage(nil, nil, notify.DeliveryChannelEmail, "from", "to", "subject", "text")
+	assert.NoError(t, err)
+
+	message.Failed(fmt.Errorf("boom"), now, backoff, 2)
+	assert.Equal(t, notify.OutboxStatusPending, message.Status)
+	assert.Equal(t, 1, message.Attempts)
+	assert.Equal(t, now.Add(time.Minute), message.NextAttemptAt)
+	assert.Equal(t, "boom", message.LastError)
+
+	message.Failed(fmt.Errorf("boom"), now, backoff, 2)
+	assert.Equal(t, notify.OutboxStatusDead, message.Status)
+	assert.Equal(t, 2, message.Attempts)
+}
+
+func TestOutboxWorker(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := notify.NewOutboxRepository(db)
+	deliveryRepository := notify.NewNotificationDeliveryRepository(db)
+	option := notify.OutboxWorkerOption{
+		MaxAttempts: 3,
+		Backoff:     notify.Backoff{Base: time.Minute, Max: time.Hour},
+		BatchSize:   2,
+		Lease:       time.Minute,
+	}
+	now := time.Now().UTC()
+
+	t.Run("send all due messages", func(t *testing.T) {
+		messages := make([]*notify.OutboxMessage, 0, 3)
+		for i := range 3 {
+			message, err := notify.NewOutboxMessage(nil, nil, notify.DeliveryChannelEmail, "from", fmt.Sprintf("to-%d", i), "subject", "text")
+			assert.NoError(t, err)
+			messages = append(messages, message)
+		}
+		err := repo.Save(ctx, messages)
+		assert.NoError(t, err)
+
+		mail := &fakeMailService{}
+		attempted, err := notify.NewOutboxWorker(emailChannels(mail), repo, deliveryRepository, option).Drain(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempted)
+		assert.Equal(t, 3, len(mail.sent))
+
+		pending, err := repo.GetByStatus(ctx, notify.OutboxStatusPending)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(pending))
+	})
+
+	t.Run("back off and dead letter", func(t *testing.T) {
+		message, err := notify.NewOutboxMessage(nil, nil, notify.DeliveryChannelEmail, "from", "dead@example.com", "subject", "text")
+		assert.NoError(t, err)
+		err = repo.Save(ctx, []*notify.OutboxMessage{message})
+		assert.NoError(t, err)
+		worker := notify.NewOutboxWorker(
+			emailChannels(&fakeMailService{err: fmt.Errorf("rejected")}), repo, deliveryRepository, option)
+
+		attempted, err := worker.Drain(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		attempted, err = worker.Drain(ctx, now.Add(30*time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, attempted)
+
+		_, err = worker.Drain(ctx, now.Add(time.Minute))
+		assert.NoError(t, err)
+		_, err = worker.Drain(ctx, now.Add(3*time.Minute))
+		assert.NoError(t, err)
+
+		dead, err := repo.GetByStatus(ctx, notify.OutboxStatusDead)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(dead))
+		assert.Equal(t, message.ID, dead[0].ID)
+		assert.Equal(t, 3, dead[0].Attempts)
+		assert.Equal(t, "rejected", dead[0].LastError)
+	})
+}
@@ -0,0 +1,61 @@
+package notifystock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MarketDataProvider fetches the daily prices of a symbol from a market data
+// source.
+type MarketDataProvider interface {
+	Name() string
+	FetchStock(ctx context.Context, symbol string, start, end time.Time) (*Stocks, error)
+}
+
+// NewMarketDataProvider returns the providers used to register prices: Yahoo
+// Finance, falling back to Stooq, at the base URLs of the config.
+func NewMarketDataProvider(client HTTPClientInterface) MarketDataProvider {
+	return NewFailoverProvider(
+		NewFinanceClient(client, Cfg.YahooBaseURL),
+		NewStooqClient(client, Cfg.StooqBaseURL),
+	)
+}
+
+// FailoverProvider tries its providers in order and returns the prices of the
+// first one that succeeds.
+type FailoverProvider struct {
+	providers []MarketDataProvider
+}
+
+func NewFailoverProvider(providers ...MarketDataProvider) *FailoverProvider {
+	return &FailoverProvider{
+		providers: providers,
+	}
+}
+
+func (f *FailoverProvider) Name() string {
+	return "failover"
+}
+
+func (f *FailoverProvider) FetchStock(
+	ctx context.Context, symbol string, start, end time.Time) (*Stocks, error) {
+	var errs []error
+	for _, provider := range f.providers {
+		stocks, err := provider.FetchStock(ctx, symbol, start, end)
+		if err == nil {
+			return stocks, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.Warn("failed to fetch stock, trying the next provider",
+			"provider", provider.Name(), "symbol", symbol, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no market data provider for %s", symbol)
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,344 @@
+package notifystock_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/heyjun3/notify-stock/internal/calendar"
+)
+
+// fixtureClient replies to each host with a recorded response under testdata.
+type fixtureClient struct {
+	fixtures map[string]string
+	requests []*http.Request
+}
+
+func (c *fixtureClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests = append(c.requests, req)
+	path, ok := c.fixtures[req.URL.Host]
+	if !ok {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewBufferString("not found")),
+		}, nil
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+const (
+	yahooHost = "query2.finance.yahoo.com"
+	stooqHost = "stooq.com"
+)
+
+func assertN225(t *testing.T, stocks *notify.Stocks) {
+	t.Helper()
+	assert.Equal(t, []float64{33288.29, 33377.42, 33763.18}, stocks.Closes())
+	symbol := stocks.Symbol()
+	assert.Equal(t, "^N225", symbol.Symbol)
+	assert.Equal(t, "Nikkei 225", symbol.ShortName)
+	assert.Equal(t, notify.JPY, *symbol.Currency)
+	assert.Equal(t, decimal.RequireFromString("33763"), symbol.MarketPrice)
+	assert.Equal(t, decimal.RequireFromString("33377"), symbol.PreviousClose)
+}
+
+func TestMarketDataProviders(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	t.Run("yahoo", func(t *testing.T) {
+		client := &fixtureClient{fixtures: map[string]string{
+			yahooHost: "testdata/yahoo/chart_N225.json",
+		}}
+
+		stocks, err := notify.NewFinanceClient(client, notify.YahooFinanceBaseURL).FetchStock(ctx, "^N225", start, end)
+
+		assert.NoError(t, err)
+		assertN225(t, stocks)
+		symbol := stocks.Symbol()
+		assert.Equal(t, calendar.TSE, symbol.Exchange)
+		assert.Equal(t, "OSA", symbol.ExchangeName)
+		assert.Equal(t, "Osaka", symbol.FullExchangeName)
+		assert.Equal(t, notify.InstrumentTypeIndex, symbol.InstrumentType)
+		assert.Equal(t, "Asia/Tokyo", symbol.Timezone)
+		assert.Equal(t, "33853", symbol.FiftyTwoWeekHigh.Decimal.String())
+		assert.Equal(t, "32693", symbol.FiftyTwoWeekLow.Decimal.String())
+		assert.True(t, time.Unix(-157453200, 0).Equal(symbol.FirstTradeDate.Time))
+		first := stocks.Stocks()[0]
+		assert.Equal(t, int64(119400000), first.Volume.Int64)
+		assert.Equal(t, 33288.29, first.AdjClose.Float64)
+		assert.Equal(t, "/v8/finance/chart/^N225", client.requests[0].URL.Path)
+		assert.Equal(t, "1d", client.requests[0].URL.Query().Get("interval"))
+	})
+
+	t.Run("stooq", func(t *testing.T) {
+		client := &fixtureClient{fixtures: map[string]string{
+			stooqHost: "testdata/stooq/nkx.csv",
+		}}
+
+		stocks, err := notify.NewStooqClient(client, notify.StooqBaseURL).FetchStock(ctx, "^N225", start, end)
+
+		assert.NoError(t, err)
+		assertN225(t, stocks)
+		first := stocks.Stocks()[0]
+		assert.Equal(t, int64(1194000000), first.Volume.Int64)
+		assert.False(t, first.AdjClose.Valid)
+		query := client.requests[0].URL.Query()
+		assert.Equal(t, "^nkx", query.Get("s"))
+		assert.Equal(t, "20240104", query.Get("d1"))
+		assert.Equal(t, "20240109", query.Get("d2"))
+	})
+
+	t.Run("local stub", func(t *testing.T) {
+		body, err := os.ReadFile("testdata/yahoo/chart_N225.json")
+		assert.NoError(t, err)
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Write(body)
+		}))
+		defer server.Close()
+
+		stocks, err := notify.NewFinanceClient(server.Client(), server.URL+"/yahoo").
+			FetchStock(ctx, "^N225", start, end)
+
+		assert.NoError(t, err)
+		assertN225(t, stocks)
+		assert.Equal(t, "/yahoo/v8/finance/chart/^N225", path)
+	})
+
+	t.Run("stooq codes", func(t *testing.T) {
+		for symbol, code := range map[string]string{
+			"^GSPC":    "^spx",
+			"7203.T":   "7203.jp",
+			"AAPL":     "aapl.us",
+			"USDJPY=X": "usdjpy",
+		} {
+			client := &fixtureClient{}
+			_, err := notify.NewStooqClient(client, notify.StooqBaseURL).FetchStock(ctx, symbol, start, end)
+
+			assert.Error(t, err)
+			assert.Equal(t, code, client.requests[0].URL.Query().Get("s"))
+		}
+	})
+
+	t.Run("stooq leaves unknown names and currencies empty", func(t *testing.T) {
+		client := &fixtureClient{fixtures: map[string]string{
+			stooqHost: "testdata/stooq/nkx.csv",
+		}}
+
+		stocks, err := notify.NewStooqClient(client, notify.StooqBaseURL).FetchStock(ctx, "^FTSE", start, end)
+
+		assert.NoError(t, err)
+		symbol := stocks.Symbol()
+		assert.Empty(t, symbol.ShortName)
+		assert.Empty(t, symbol.LongName)
+		assert.Nil(t, symbol.Currency)
+	})
+
+	t.Run("stooq without data", func(t *testing.T) {
+		_, err := notify.ConvertStooqCSVToStock(
+			bytes.NewBufferString("No data"), "^N225", "Nikkei 225", "JPY")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("failover to the next provider", func(t *testing.T) {
+		client := &fixtureClient{fixtures: map[string]string{
+			stooqHost: "testdata/stooq/nkx.csv",
+		}}
+
+		stocks, err := notify.NewMarketDataProvider(client).FetchStock(ctx, "^N225", start, end)
+
+		assert.NoError(t, err)
+		assertN225(t, stocks)
+		assert.Len(t, client.requests, 2)
+		assert.Equal(t, yahooHost, client.requests[0].URL.Host)
+		assert.Equal(t, stooqHost, client.requests[1].URL.Host)
+	})
+
+	t.Run("first provider wins", func(t *testing.T) {
+		client := &fixtureClient{fixtures: map[string]string{
+			yahooHost: "testdata/yahoo/chart_N225.json",
+			stooqHost: "testdata/stooq/nkx.csv",
+		}}
+
+		_, err := notify.NewMarketDataProvider(client).FetchStock(ctx, "^N225", start, end)
+
+		assert.NoError(t, err)
+		assert.Len(t, client.requests, 1)
+	})
+
+	t.Run("every provider fails", func(t *testing.T) {
+		provider := notify.NewFailoverProvider(
+			failingProvider{name: "first"}, failingProvider{name: "second"},
+		)
+
+		_, err := provider.FetchStock(ctx, "^N225", start, end)
+
+		assert.ErrorContains(t, err, "first: down")
+		assert.ErrorContains(t, err, "second: down")
+	})
+}
+
+type failingProvider struct {
+	name string
+}
+
+func (p failingProvider) Name() string {
+	return p.name
+}
+
+func (p failingProvider) FetchStock(context.Context, string, time.Time, time.Time) (*notify.Stocks, error) {
+	return nil, fmt.Errorf("down")
+}
+
+func TestStockRegister(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	start := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	for name, fixtures := range map[string]map[string]string{
+		"yahoo": {yahooHost: "testdata/yahoo/chart_N225.json"},
+		"stooq": {stooqHost: "testdata/stooq/nkx.csv"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			register := notify.NewStockRegister(
+				notify.NewMarketDataProvider(&fixtureClient{fixtures: fixtures}),
+				notify.NewStockRepository(db),
+				notify.NewSymbolRepository(db),
+				notify.NewCorporateActionRepository(db),
+				notify.NewTrackedSymbolRepository(db),
+				notify.DefaultStockRegisterOption(),
+			)
+
+			err := register.RegisterStockBySymbols(ctx, []string{"^N225"}, start, end)
+			assert.NoError(t, err)
+
+			detail, err := notify.NewSymbolRepository(db).Get(ctx, "^N225")
+			assert.NoError(t, err)
+			assert.Equal(t, "Nikkei 225", detail.ShortName)
+			assert.Equal(t, decimal.RequireFromString("33763"), detail.MarketPrice)
+		})
+	}
+
+	t.Run("fallback keeps the saved metadata", func(t *testing.T) {
+		repository := notify.NewSymbolRepository(db)
+		saved := notify.NewSymbolDetail("^FTSE", "FTSE 100", "FTSE 100 Index", "GBP",
+			decimal.NewFromInt(7700), decimal.NewFromInt(7650),
+			notify.WithVolume(500000000), notify.WithMarketCap(1000000))
+		assert.NoError(t, repository.Save(ctx, []notify.SymbolDetail{*saved}))
+		register := notify.NewStockRegister(
+			notify.NewMarketDataProvider(&fixtureClient{fixtures: map[string]string{
+				stooqHost: "testdata/stooq/nkx.csv",
+			}}),
+			notify.NewStockRepository(db),
+			repository,
+			notify.NewCorporateActionRepository(db),
+			notify.NewTrackedSymbolRepository(db),
+			notify.DefaultStockRegisterOption(),
+		)
+
+		err := register.RegisterStockBySymbol(ctx, "^FTSE", start, end)
+		assert.NoError(t, err)
+
+		detail, err := repository.Get(ctx, "^FTSE")
+		assert.NoError(t, err)
+		assert.Equal(t, "FTSE 100", detail.ShortName)
+		assert.Equal(t, "FTSE 100 Index", detail.LongName)
+		assert.Equal(t, notify.GBP, *detail.Currency)
+		assert.Equal(t, int64(500000000), detail.Volume.Int64)
+		assert.Equal(t, int64(1000000), detail.MarketCap.Int64)
+		assert.Equal(t, decimal.RequireFromString("33763.18"), detail.MarketPrice)
+	})
+}
+
+// blockingProvider fails every fetch after a while, tracking how many fetches
+// run at the same time.
+type blockingProvider struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (p *blockingProvider) Name() string {
+	return "blocking"
+}
+
+func (p *blockingProvider) FetchStock(ctx context.Context, symbol string, _, _ time.Time) (*notify.Stocks, error) {
+	p.mu.Lock()
+	p.running++
+	p.peak = max(p.peak, p.running)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(10 * time.Millisecond):
+		return nil, fmt.Errorf("down")
+	}
+}
+
+func TestStockRegisterPool(t *testing.T) {
+	symbols := []string{"^N225", "^GSPC", "^DJI", "^IXIC", "^XDN", "7203.T"}
+	now := time.Now()
+
+	t.Run("report failed symbols in order", func(t *testing.T) {
+		provider := &blockingProvider{}
+		register := notify.NewStockRegister(provider, nil, nil, nil, nil, notify.StockRegisterOption{
+			Concurrency: 3, Timeout: time.Second,
+		})
+
+		err := register.RegisterStockBySymbols(context.Background(), symbols, now, now)
+
+		assert.ErrorContains(t, err, "^GSPC: down")
+		assert.Equal(t, symbols, notify.FailedSymbols(err))
+		assert.Equal(t, 3, provider.peak)
+	})
+
+	t.Run("timeout per symbol", func(t *testing.T) {
+		register := notify.NewStockRegister(&blockingProvider{}, nil, nil, nil, nil, notify.StockRegisterOption{
+			Concurrency: 2, Timeout: time.Millisecond,
+		})
+
+		err := register.RegisterStockBySymbols(context.Background(), symbols, now, now)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, symbols, notify.FailedSymbols(err))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		register := notify.NewStockRegister(&blockingProvider{}, nil, nil, nil, nil, notify.DefaultStockRegisterOption())
+
+		err := register.RegisterStockBySymbols(ctx, symbols, now, now)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, symbols, notify.FailedSymbols(err))
+	})
+}
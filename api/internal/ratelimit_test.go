@@ -0,0 +1,36 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("burst then refill", func(t *testing.T) {
+		limiter := notify.NewRateLimiter(50, 2)
+		start := time.Now()
+
+		for range 4 {
+			assert.NoError(t, limiter.Wait(context.Background()))
+		}
+
+		// two tokens are in the bucket, the other two refill at 20ms each
+		assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		limiter := notify.NewRateLimiter(0.01, 1)
+		assert.NoError(t, limiter.Wait(context.Background()))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := limiter.Wait(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
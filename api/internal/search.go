@@ -0,0 +1,186 @@
+package notifystock
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SymbolCandidate is a symbol found by a search, which may not be tracked
+// yet.
+type SymbolCandidate struct {
+	Symbol         string
+	ShortName      string
+	LongName       string
+	ExchangeName   string
+	InstrumentType InstrumentType
+}
+
+// SymbolSearcher finds the symbols whose ticker or name matches a query.
+type SymbolSearcher interface {
+	SearchSymbols(ctx context.Context, query string) ([]SymbolCandidate, error)
+}
+
+// NewSymbolSearcher returns Yahoo Finance at the base URL of the config.
+func NewSymbolSearcher(client HTTPClientInterface) SymbolSearcher {
+	return NewFinanceClient(client, Cfg.YahooBaseURL)
+}
+
+const searchLimit = 10
+
+type SearchResponse struct {
+	Quotes []SearchQuote `json:"quotes"`
+}
+type SearchQuote struct {
+	Symbol    string `json:"symbol"`
+	ShortName string `json:"shortname"`
+	LongName  string `json:"longname"`
+	Exchange  string `json:"exchange"`
+	QuoteType string `json:"quoteType"`
+}
+
+// SearchSymbols searches the symbols of Yahoo Finance, leaving out the
+// results of the instrument types that are not supported.
+func (c *FinanceClient) SearchSymbols(ctx context.Context, query string) ([]SymbolCandidate, error) {
+	URL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	URL = URL.JoinPath("v1/finance/search")
+	params := URL.Query()
+	params.Add("q", query)
+	params.Add("quotesCount", strconv.Itoa(searchLimit))
+	params.Add("newsCount", "0")
+	URL.RawQuery = params.Encode()
+
+	body, err := fetch(ctx, c.Client, c.Retry, c.Name(), query, URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var res SearchResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, c.malformed(query, err)
+	}
+	candidates := make([]SymbolCandidate, 0, len(res.Quotes))
+	for _, quote := range res.Quotes {
+		instrumentType := InstrumentType(strings.ToLower(quote.QuoteType))
+		if quote.Symbol == "" || !instrumentType.IsValid() {
+			continue
+		}
+		candidates = append(candidates, SymbolCandidate{
+			Symbol:         quote.Symbol,
+			ShortName:      quote.ShortName,
+			LongName:       quote.LongName,
+			ExchangeName:   quote.Exchange,
+			InstrumentType: instrumentType,
+		})
+	}
+	return candidates, nil
+}
+
+// backfillYears is how far back the prices of a newly tracked symbol are
+// registered.
+const backfillYears = 5
+
+type SymbolTrackerOption struct {
+	// Timeout bounds the registration of a newly tracked symbol, which runs
+	// within the request.
+	Timeout time.Duration
+	// MaxSymbolsPerMember is how many symbols a member can add.
+	MaxSymbolsPerMember int
+}
+
+func DefaultSymbolTrackerOption() SymbolTrackerOption {
+	return SymbolTrackerOption{
+		Timeout:             20 * time.Second,
+		MaxSymbolsPerMember: 10,
+	}
+}
+
+// SymbolTracker lets members find symbols and start tracking them.
+type SymbolTracker struct {
+	searcher                SymbolSearcher
+	register                *StockRegister
+	symbolRepository        *SymbolRepository
+	trackedSymbolRepository *TrackedSymbolRepository
+	option                  SymbolTrackerOption
+}
+
+func NewSymbolTracker(
+	searcher SymbolSearcher,
+	register *StockRegister,
+	symbolRepository *SymbolRepository,
+	trackedSymbolRepository *TrackedSymbolRepository,
+	option SymbolTrackerOption,
+) *SymbolTracker {
+	return &SymbolTracker{
+		searcher:                searcher,
+		register:                register,
+		symbolRepository:        symbolRepository,
+		trackedSymbolRepository: trackedSymbolRepository,
+		option:                  option,
+	}
+}
+
+func (t *SymbolTracker) Search(ctx context.Context, query string) ([]SymbolCandidate, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, NewValidationError("Invalid query", "query is empty")
+	}
+	return t.searcher.SearchSymbols(ctx, query)
+}
+
+// Track registers the prices of the last five years of the symbol and adds it
+// to the registry, so that members can be notified of it. A symbol that is
+// already tracked is returned as is. A symbol disabled by an administrator
+// is not enabled again, and a member can only add a limited number of
+// symbols.
+func (t *SymbolTracker) Track(
+	ctx context.Context, memberID uuid.UUID, symbol string, now time.Time) (*SymbolDetail, error) {
+	tracked, err := NewTrackedSymbol(symbol, &memberID, 0, now)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := t.trackedSymbolRepository.Get(ctx, tracked.Symbol)
+	switch {
+	case err == nil && existing.Enabled:
+		return t.symbolRepository.Get(ctx, tracked.Symbol)
+	case err == nil:
+		return nil, NewValidationError("Disabled symbol", fmt.Sprintf("symbol %q is disabled", tracked.Symbol))
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+	added, err := t.trackedSymbolRepository.CountAddedBy(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if added >= t.option.MaxSymbolsPerMember {
+		return nil, NewValidationError("Too many symbols",
+			fmt.Sprintf("a member can add up to %d symbols", t.option.MaxSymbolsPerMember))
+	}
+	registerCtx := ctx
+	if t.option.Timeout > 0 {
+		var cancel context.CancelFunc
+		registerCtx, cancel = context.WithTimeout(ctx, t.option.Timeout)
+		defer cancel()
+	}
+	if err := t.register.RegisterStockBySymbol(
+		registerCtx, tracked.Symbol, now.AddDate(-backfillYears, 0, 0), now); err != nil {
+		if errors.Is(err, ErrSymbolNotFound) {
+			return nil, NewValidationError("Unknown symbol", fmt.Sprintf("symbol %q is not found", tracked.Symbol))
+		}
+		return nil, err
+	}
+	if err := t.trackedSymbolRepository.Save(ctx, []*TrackedSymbol{tracked}); err != nil {
+		return nil, err
+	}
+	return t.symbolRepository.Get(ctx, tracked.Symbol)
+}
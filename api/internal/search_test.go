@@ -0,0 +1,136 @@
+package notifystock_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestSearchSymbols(t *testing.T) {
+	ctx := context.Background()
+	body, err := os.ReadFile("testdata/yahoo/search_apple.json")
+	assert.NoError(t, err)
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	candidates, err := notify.NewFinanceClient(server.Client(), server.URL).SearchSymbols(ctx, "apple")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "apple", query.Get("q"))
+	assert.Equal(t, "0", query.Get("newsCount"))
+	// the option and the result without symbol are left out
+	assert.Equal(t, []notify.SymbolCandidate{
+		{
+			Symbol: "AAPL", ShortName: "Apple Inc.", LongName: "Apple Inc.",
+			ExchangeName: "NMS", InstrumentType: notify.InstrumentTypeEquity,
+		},
+		{
+			Symbol: "AAPL.NE", ShortName: "APPLE CDR (CAD HEDGED)", LongName: "Apple Inc.",
+			ExchangeName: "NEO", InstrumentType: notify.InstrumentTypeEquity,
+		},
+	}, candidates)
+}
+
+func TestSymbolTracker(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	member := createMember(t, notify.NewMemberRepository(db))
+	now := time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC)
+	newTracker := func(fixtures map[string]string, option notify.SymbolTrackerOption) *notify.SymbolTracker {
+		client := &fixtureClient{fixtures: fixtures}
+		symbolRepository := notify.NewSymbolRepository(db)
+		trackedSymbolRepository := notify.NewTrackedSymbolRepository(db)
+		register := notify.NewStockRegister(
+			notify.NewMarketDataProvider(client),
+			notify.NewStockRepository(db),
+			symbolRepository,
+			notify.NewCorporateActionRepository(db),
+			trackedSymbolRepository,
+			notify.DefaultStockRegisterOption(),
+		)
+		return notify.NewSymbolTracker(
+			notify.NewFinanceClient(client, notify.YahooFinanceBaseURL),
+			register, symbolRepository, trackedSymbolRepository, option,
+		)
+	}
+
+	t.Run("track", func(t *testing.T) {
+		tracker := newTracker(map[string]string{yahooHost: "testdata/yahoo/chart_AAPL_events.json"},
+			notify.DefaultSymbolTrackerOption())
+
+		detail, err := tracker.Track(ctx, member.ID, "aapl", now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "AAPL", detail.Symbol)
+		tracked, err := notify.NewTrackedSymbolRepository(db).Get(ctx, "AAPL")
+		assert.NoError(t, err)
+		assert.True(t, tracked.Enabled)
+		assert.Equal(t, member.ID, tracked.AddedBy.UUID)
+		stocks, err := notify.NewStockRepository(db).GetStockByPeriod(
+			ctx, "AAPL", now.AddDate(-5, 0, 0), now)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, stocks)
+
+		_, err = notify.NewNotificationCreator(
+			notify.NewNotificationRepository(db),
+			notify.NewSymbolRepository(db),
+			notify.NewTrackedSymbolRepository(db),
+			notify.NewMemberRepository(db),
+		).Create(ctx, member.ID, []string{"AAPL"}, now, nil, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("limit symbols per member", func(t *testing.T) {
+		tracker := newTracker(nil, notify.SymbolTrackerOption{MaxSymbolsPerMember: 1})
+
+		_, err := tracker.Track(ctx, member.ID, "MSFT", now)
+
+		var appErr *notify.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "Too many symbols", appErr.Message)
+	})
+
+	t.Run("keep a disabled symbol disabled", func(t *testing.T) {
+		repository := notify.NewTrackedSymbolRepository(db)
+		assert.NoError(t, repository.Disable(ctx, "AAPL", now))
+
+		_, err := newTracker(nil, notify.DefaultSymbolTrackerOption()).Track(ctx, member.ID, "AAPL", now)
+
+		var appErr *notify.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "Disabled symbol", appErr.Message)
+		tracked, err := repository.Get(ctx, "AAPL")
+		assert.NoError(t, err)
+		assert.False(t, tracked.Enabled)
+	})
+
+	t.Run("invalid ticker", func(t *testing.T) {
+		_, err := newTracker(nil, notify.DefaultSymbolTrackerOption()).Track(ctx, member.ID, "AAPL; DROP TABLE", now)
+
+		var appErr *notify.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, notify.ErrCodeValidation, appErr.Code)
+	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		_, err := newTracker(nil, notify.DefaultSymbolTrackerOption()).Track(ctx, member.ID, "UNKNOWN", now)
+
+		var appErr *notify.AppError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, "Unknown symbol", appErr.Message)
+		_, err = notify.NewTrackedSymbolRepository(db).Get(ctx, "UNKNOWN")
+		assert.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package notifystock
 import (
 	"cmp"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"slices"
@@ -13,8 +14,35 @@ import (
 	"github.com/uptrace/bun"
 )
 
+type StockOption func(stock *Stock) *Stock
+
+func WithStockVolume(volume int64) StockOption {
+	return func(stock *Stock) *Stock {
+		stock.Volume = sql.NullInt64{Int64: volume, Valid: true}
+		return stock
+	}
+}
+
+// WithAdjClose sets the close adjusted for splits and dividends.
+func WithAdjClose(adjClose float64) StockOption {
+	return func(stock *Stock) *Stock {
+		if adjClose > 0 {
+			stock.AdjClose = sql.NullFloat64{Float64: adjClose, Valid: true}
+		}
+		return stock
+	}
+}
+
+// WithFetchedAt records when the bar was fetched from the provider.
+func WithFetchedAt(fetchedAt time.Time) StockOption {
+	return func(stock *Stock) *Stock {
+		stock.FetchedAt = fetchedAt
+		return stock
+	}
+}
+
 func NewStock(symbol string, timestamp time.Time,
-	open, close, high, low float64) (Stock, error) {
+	open, close, high, low float64, options ...StockOption) (Stock, error) {
 	for _, v := range []float64{open, close, high, low} {
 		if v <= 0 {
 			return Stock{}, fmt.Errorf(
@@ -23,14 +51,18 @@ func NewStock(symbol string, timestamp time.Time,
 		}
 	}
 	truncated := timestamp.Truncate(time.Hour * 24)
-	return Stock{
+	stock := Stock{
 		Symbol:    symbol,
 		Timestamp: truncated,
 		Open:      open,
 		Close:     close,
 		High:      high,
 		Low:       low,
-	}, nil
+	}
+	for _, option := range options {
+		option(&stock)
+	}
+	return stock, nil
 }
 
 type Stock struct {
@@ -42,11 +74,30 @@ type Stock struct {
 	Close     float64   `bun:"close,type:decimal,notnull"`
 	High      float64   `bun:"high,type:decimal,notnull"`
 	Low       float64   `bun:"low,type:decimal,notnull"`
+	// Volume and AdjClose are null on bars registered before they were
+	// stored, or by providers that do not serve them.
+	Volume   sql.NullInt64   `bun:"volume"`
+	AdjClose sql.NullFloat64 `bun:"adj_close,type:decimal"`
+	// FetchedAt is when the bar was fetched. Providers serve the prices
+	// adjusted for the splits until then, so only the bars fetched before a
+	// split are adjusted for it. A zero one is saved as the current time.
+	FetchedAt time.Time `bun:"fetched_at,type:timestamp,nullzero,notnull,default:now()"`
+}
+
+// AdjustedClose returns the close adjusted for splits and dividends, or the
+// close when it is unknown.
+func (s Stock) AdjustedClose() float64 {
+	if s.AdjClose.Valid {
+		return s.AdjClose.Float64
+	}
+	return s.Close
 }
 
 type Stocks struct {
-	symbol SymbolDetail
-	stocks []Stock
+	symbol    SymbolDetail
+	stocks    []Stock
+	dividends []Dividend
+	splits    []Split
 }
 
 func NewStocks(symbol SymbolDetail, stocks []Stock) (*Stocks, error) {
@@ -56,6 +107,24 @@ func NewStocks(symbol SymbolDetail, stocks []Stock) (*Stocks, error) {
 	}, nil
 }
 
+func (s *Stocks) Symbol() SymbolDetail {
+	return s.symbol
+}
+
+func (s *Stocks) Stocks() []Stock {
+	return s.stocks
+}
+
+// Dividends returns the dividends paid in the fetched period.
+func (s *Stocks) Dividends() []Dividend {
+	return s.dividends
+}
+
+// Splits returns the splits in the fetched period.
+func (s *Stocks) Splits() []Split {
+	return s.splits
+}
+
 func (s *Stocks) Latest() Stock {
 	return slices.MaxFunc(s.stocks, func(a, b Stock) int {
 		return a.Timestamp.Compare(b.Timestamp)
@@ -80,23 +149,182 @@ func (s *Stocks) ClosingPriceToAVGRatio() (decimal.Decimal, error) {
 	return ratio, nil
 }
 
-func (s *Stocks) GenerateNotificationMessage() (string, error) {
-	avg, err := s.ClosingAverage()
+// sorted returns the stocks ordered from the oldest to the latest.
+func (s *Stocks) sorted() []Stock {
+	return slices.SortedFunc(slices.Values(s.stocks), func(a, b Stock) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+}
+
+// Closes returns the closing prices ordered from the oldest to the latest.
+func (s *Stocks) Closes() []float64 {
+	stocks := s.sorted()
+	close := make([]float64, 0, len(stocks))
+	for _, v := range stocks {
+		close = append(close, v.Close)
+	}
+	return close
+}
+
+// lookbackSince returns a day early enough for the period until now to hold
+// days trading days on any exchange: two calendar days per trading day leave
+// room for weekends and holidays.
+func lookbackSince(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -(days*2 + 7))
+}
+
+// MovingAverage returns the average close of the last window trading days,
+// ending offset trading days before the latest one.
+func (s *Stocks) MovingAverage(window, offset int) (decimal.Decimal, error) {
+	close := s.Closes()
+	end := len(close) - offset
+	if window <= 0 || offset < 0 || end-window < 0 {
+		return decimal.Decimal{}, fmt.Errorf(
+			"not enough stocks for %d-day moving average. symbol: %s, stocks: %d",
+			window, s.symbol.Symbol, len(close))
+	}
+	return CalcAVG(close[end-window : end])
+}
+
+// PriceToMovingAverageRatio returns the close divided by the window trading
+// day moving average, offset trading days before the latest one.
+func (s *Stocks) PriceToMovingAverageRatio(window, offset int) (decimal.Decimal, error) {
+	avg, err := s.MovingAverage(window, offset)
 	if err != nil {
-		return "", err
+		return decimal.Decimal{}, err
 	}
-	latest := s.Latest()
-	fmt.Println("latest", latest.Close)
-	ratio, err := s.ClosingPriceToAVGRatio()
+	close := s.Closes()
+	return decimal.NewFromFloat(close[len(close)-1-offset]).Div(avg), nil
+}
+
+type Crossover string
+
+const (
+	CrossoverNone Crossover = ""
+	// CrossoverGolden is the short moving average crossing above the long one.
+	CrossoverGolden Crossover = "golden_cross"
+	// CrossoverDeath is the short moving average crossing below the long one.
+	CrossoverDeath Crossover = "death_cross"
+)
+
+// Crossover reports how the short moving average crossed the long one on the
+// latest trading day.
+func (s *Stocks) Crossover(short, long int) (Crossover, error) {
+	var spreads [2]decimal.Decimal
+	for offset := range spreads {
+		shortAVG, err := s.MovingAverage(short, offset)
+		if err != nil {
+			return CrossoverNone, err
+		}
+		longAVG, err := s.MovingAverage(long, offset)
+		if err != nil {
+			return CrossoverNone, err
+		}
+		spreads[offset] = shortAVG.Sub(longAVG)
+	}
+	latest, previous := spreads[0], spreads[1]
+	switch {
+	case !previous.IsPositive() && latest.IsPositive():
+		return CrossoverGolden, nil
+	case !previous.IsNegative() && latest.IsNegative():
+		return CrossoverDeath, nil
+	}
+	return CrossoverNone, nil
+}
+
+type MovingAverageWindow string
+
+const (
+	MovingAverageWindow25Days   MovingAverageWindow = "days_25"
+	MovingAverageWindow75Days   MovingAverageWindow = "days_75"
+	MovingAverageWindow200Days  MovingAverageWindow = "days_200"
+	MovingAverageWindow3Months  MovingAverageWindow = "months_3"
+	MovingAverageWindow6Months  MovingAverageWindow = "months_6"
+	MovingAverageWindow12Months MovingAverageWindow = "months_12"
+
+	DefaultMovingAverageWindow = MovingAverageWindow12Months
+)
+
+func (w MovingAverageWindow) IsValid() bool {
+	_, _, ok := w.size()
+	return ok
+}
+
+// size returns the window in trading days or in months.
+func (w MovingAverageWindow) size() (days int, months int, ok bool) {
+	switch w {
+	case MovingAverageWindow25Days:
+		return 25, 0, true
+	case MovingAverageWindow75Days:
+		return 75, 0, true
+	case MovingAverageWindow200Days:
+		return 200, 0, true
+	case MovingAverageWindow3Months:
+		return 0, 3, true
+	case MovingAverageWindow6Months:
+		return 0, 6, true
+	case MovingAverageWindow12Months:
+		return 0, 12, true
+	}
+	return 0, 0, false
+}
+
+// Label names the window in the notification message, e.g. "25-Day".
+func (w MovingAverageWindow) Label(locale Locale) string {
+	days, months, _ := w.size()
+	switch {
+	case days > 0:
+		return locale.T("window.days", days)
+	case months == 12:
+		return locale.T("window.year")
+	}
+	return locale.T("window.months", months)
+}
+
+// Since returns the first day of the stocks needed to average over the window
+// ending at now.
+func (w MovingAverageWindow) Since(now time.Time) time.Time {
+	days, months, _ := w.size()
+	if days > 0 {
+		return lookbackSince(now, days)
+	}
+	return now.AddDate(0, -months, 0)
+}
+
+// WindowAverage returns the average close over the window ending at the
+// latest stock.
+func (s *Stocks) WindowAverage(window MovingAverageWindow) (decimal.Decimal, error) {
+	days, months, ok := window.size()
+	if !ok {
+		return decimal.Decimal{}, fmt.Errorf("unsupported moving average window: %q", window)
+	}
+	if days > 0 {
+		return s.MovingAverage(days, 0)
+	}
+	since := s.Latest().Timestamp.AddDate(0, -months, 0)
+	close := make([]float64, 0, len(s.stocks))
+	for _, v := range s.stocks {
+		if v.Timestamp.After(since) {
+			close = append(close, v.Close)
+		}
+	}
+	return CalcAVG(close)
+}
+
+func (s *Stocks) GenerateNotificationMessage(window MovingAverageWindow, locale Locale) (string, error) {
+	avg, err := s.WindowAverage(window)
 	if err != nil {
 		return "", err
 	}
+	latest := decimal.NewFromFloat(s.Latest().Close)
+	ratio := latest.Div(avg)
 	currency := s.symbol.Currency
+	label := window.Label(locale)
 	text := strings.Join([]string{
 		s.symbol.ShortName,
-		fmt.Sprintf("Closing Price: %v %s", int(latest.Close), currency),
-		fmt.Sprintf("1-Year Moving Average: %v %s", avg.Ceil(), currency),
-		fmt.Sprintf("Closing Price to 1-Year Moving Average Ratio: %v%s", ratio.Mul(decimal.New(100, 0)).RoundCeil(2), "%"),
+		locale.T("summary.close", locale.FormatPrice(latest, currency)),
+		locale.T("summary.moving_average", label, locale.FormatPrice(avg, currency)),
+		locale.T("summary.ratio", label, ratio.Mul(decimal.New(100, 0)).RoundCeil(2).String()+"%"),
 	}, "\n")
 	return text, nil
 }
@@ -148,6 +376,9 @@ func (r *StockRepository) Save(ctx context.Context, stocks []Stock) error {
 			"close = EXCLUDED.close",
 			"high = EXCLUDED.high",
 			"low = EXCLUDED.low",
+			"volume = COALESCE(EXCLUDED.volume, stock.volume)",
+			"adj_close = COALESCE(EXCLUDED.adj_close, stock.adj_close)",
+			"fetched_at = EXCLUDED.fetched_at",
 		}, ",")).
 		Exec(ctx)
 	return err
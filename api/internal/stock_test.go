@@ -7,6 +7,7 @@ import (
 	"time"
 
 	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,6 +39,39 @@ func TestSave(t *testing.T) {
 	}
 }
 
+func TestSaveKeepsVolumeAndAdjClose(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := notify.NewStockRepository(db)
+	timestamp := time.Now().AddDate(0, 0, -1)
+	withVolume, err := notify.NewStock("N225", timestamp, 1000, 2000, 2500, 500,
+		notify.WithStockVolume(1200), notify.WithAdjClose(1990))
+	assert.NoError(t, err)
+	withoutVolume, err := notify.NewStock("N225", timestamp, 1000, 2010, 2500, 500)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.Save(ctx, []notify.Stock{withVolume}))
+	// a provider without volume and adjusted close leaves them as they are
+	assert.NoError(t, repo.Save(ctx, []notify.Stock{withoutVolume}))
+
+	stocks, err := repo.GetStockByPeriod(ctx, "N225", timestamp.AddDate(0, 0, -1), time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, stocks, 1)
+	assert.Equal(t, 2010.0, stocks[0].Close)
+	assert.Equal(t, int64(1200), stocks[0].Volume.Int64)
+	assert.Equal(t, 1990.0, stocks[0].AdjustedClose())
+}
+
+func TestStockAdjustedClose(t *testing.T) {
+	stock, err := notify.NewStock("N225", time.Now(), 1000, 2000, 2500, 500)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000.0, stock.AdjustedClose())
+
+	stock, err = notify.NewStock("N225", time.Now(), 1000, 2000, 2500, 500, notify.WithAdjClose(1950))
+	assert.NoError(t, err)
+	assert.Equal(t, 1950.0, stock.AdjustedClose())
+}
+
 func TestGetStockByPeriod(t *testing.T) {
 	db := openDB(t)
 	repo := notify.NewStockRepository(db)
@@ -137,6 +171,149 @@ func TestStocksLatest(t *testing.T) {
 	})
 }
 
+func newDailyStocks(closes ...float64) []notify.Stock {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stocks := make([]notify.Stock, 0, len(closes))
+	for i, close := range closes {
+		stocks = append(stocks, notify.Stock{
+			Symbol:    "N225",
+			Timestamp: start.AddDate(0, 0, i),
+			Close:     close,
+		})
+	}
+	return stocks
+}
+
+func TestStocksMovingAverage(t *testing.T) {
+	s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(1, 2, 3, 4, 5))
+	assert.NoError(t, err)
+
+	avg, err := s.MovingAverage(3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "4", avg.String())
+
+	avg, err = s.MovingAverage(3, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", avg.String())
+
+	ratio, err := s.PriceToMovingAverageRatio(5, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.6666666666666667", ratio.String())
+
+	_, err = s.MovingAverage(5, 1)
+	assert.Error(t, err)
+}
+
+func TestStocksCrossover(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		want   notify.Crossover
+	}{
+		{"golden cross", []float64{10, 10, 9, 8, 14}, notify.CrossoverGolden},
+		{"death cross", []float64{8, 8, 9, 10, 4}, notify.CrossoverDeath},
+		{"stays above", []float64{1, 2, 3, 4, 5}, notify.CrossoverNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := notify.NewStocks(notify.SymbolDetail{}, newDailyStocks(tt.closes...))
+			assert.NoError(t, err)
+
+			got, err := s.Crossover(1, 3)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStocksGenerateNotificationMessage(t *testing.T) {
+	symbol := notify.NewSymbolDetail("N225", "NIKKEI 225", "NIKKEI", "JPY", decimal.NewFromInt(4), decimal.NewFromInt(3))
+	stocks := newDailyStocks(1, 2, 3, 4)
+
+	tests := []struct {
+		name   string
+		window notify.MovingAverageWindow
+		want   []string
+	}{{
+		name:   "trading days",
+		window: notify.MovingAverageWindow("days_3"),
+	}, {
+		name:   "one year",
+		window: notify.MovingAverageWindow12Months,
+		want: []string{
+			"Closing Price: ¥4",
+			"1-Year Moving Average: ¥3",
+			"Closing Price to 1-Year Moving Average Ratio: 160%",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := notify.NewStocks(*symbol, stocks)
+			assert.NoError(t, err)
+
+			message, err := s.GenerateNotificationMessage(tt.window, notify.LocaleEN)
+
+			if tt.want == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, message, want)
+			}
+		})
+	}
+
+	t.Run("25 trading days", func(t *testing.T) {
+		closes := make([]float64, 0, 30)
+		for i := range 30 {
+			closes = append(closes, float64(i+1))
+		}
+		s, err := notify.NewStocks(*symbol, newDailyStocks(closes...))
+		assert.NoError(t, err)
+
+		message, err := s.GenerateNotificationMessage(notify.MovingAverageWindow25Days, notify.LocaleEN)
+
+		assert.NoError(t, err)
+		assert.Contains(t, message, "25-Day Moving Average: ¥18")
+		assert.Contains(t, message, "Closing Price to 25-Day Moving Average Ratio: 166.67%")
+	})
+
+	t.Run("japanese", func(t *testing.T) {
+		s, err := notify.NewStocks(*symbol, stocks)
+		assert.NoError(t, err)
+
+		message, err := s.GenerateNotificationMessage(notify.MovingAverageWindow3Months, notify.LocaleJA)
+
+		assert.NoError(t, err)
+		assert.Contains(t, message, "終値: 4円")
+		assert.Contains(t, message, "3ヶ月移動平均: 3円")
+		assert.Contains(t, message, "終値の3ヶ月移動平均比: 160%")
+	})
+
+	t.Run("prices with cents", func(t *testing.T) {
+		aapl := notify.NewSymbolDetail("AAPL", "Apple", "Apple Inc.", "USD", decimal.NewFromInt(4), decimal.NewFromInt(3))
+		s, err := notify.NewStocks(*aapl, newDailyStocks(10.25, 10.5, 10.75, 11.125))
+		assert.NoError(t, err)
+
+		message, err := s.GenerateNotificationMessage(notify.MovingAverageWindow12Months, notify.LocaleEN)
+
+		assert.NoError(t, err)
+		assert.Contains(t, message, "Closing Price: $11.13")
+		assert.Contains(t, message, "1-Year Moving Average: $10.66")
+	})
+
+	t.Run("not enough trading days", func(t *testing.T) {
+		s, err := notify.NewStocks(*symbol, stocks)
+		assert.NoError(t, err)
+
+		_, err = s.GenerateNotificationMessage(notify.MovingAverageWindow25Days, notify.LocaleEN)
+
+		assert.Error(t, err)
+	})
+}
+
 func TestTimeCompare(t *testing.T) {
 	t.Run("after", func(t *testing.T) {
 		assert.True(t, time.Now().After(time.Now().AddDate(-1, 0, 0)))
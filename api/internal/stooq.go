@@ -0,0 +1,161 @@
+package notifystock
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type stooqSymbol struct {
+	code     string
+	name     string
+	currency string
+}
+
+// stooqSymbols maps the Yahoo Finance symbols of the indices to their Stooq
+// codes, since the Stooq CSV carries neither names nor currencies. The names
+// and currencies of the other symbols are left empty unless the symbol tells
+// them, so that the saved ones are kept.
+var stooqSymbols = map[string]stooqSymbol{
+	"^N225": {code: "^nkx", name: "Nikkei 225", currency: "JPY"},
+	"^GSPC": {code: "^spx", name: "S&P 500", currency: "USD"},
+	"^DJI":  {code: "^dji", name: "Dow Jones Industrial Average", currency: "USD"},
+	"^IXIC": {code: "^ndq", name: "NASDAQ Composite", currency: "USD"},
+}
+
+const StooqBaseURL = "https://stooq.com"
+
+// StooqClient fetches daily prices from the Stooq CSV download.
+type StooqClient struct {
+	Client  HTTPClientInterface
+	BaseURL string
+	Retry   RetryPolicy
+}
+
+func NewStooqClient(client HTTPClientInterface, baseURL string) *StooqClient {
+	return &StooqClient{
+		Client:  client,
+		BaseURL: baseURL,
+		Retry:   DefaultRetryPolicy(),
+	}
+}
+
+func (c *StooqClient) Name() string {
+	return "stooq"
+}
+
+// lookup returns the Stooq code of the Yahoo Finance symbol, e.g. "7203.T"
+// is "7203.jp", "AAPL" is "aapl.us" and "USDJPY=X" is "usdjpy".
+func (c *StooqClient) lookup(symbol string) stooqSymbol {
+	if s, ok := stooqSymbols[symbol]; ok {
+		return s
+	}
+	if code, ok := strings.CutSuffix(symbol, ".T"); ok {
+		return stooqSymbol{code: strings.ToLower(code) + ".jp", currency: "JPY"}
+	}
+	if pair, ok := strings.CutSuffix(symbol, "=X"); ok && len(pair) == 6 {
+		return stooqSymbol{code: strings.ToLower(pair), currency: pair[3:]}
+	}
+	if strings.HasPrefix(symbol, "^") || strings.Contains(symbol, ".") {
+		return stooqSymbol{code: strings.ToLower(symbol)}
+	}
+	return stooqSymbol{code: strings.ToLower(symbol) + ".us", currency: "USD"}
+}
+
+func (c *StooqClient) FetchStock(
+	ctx context.Context, symbol string, start, end time.Time) (*Stocks, error) {
+	s := c.lookup(symbol)
+	URL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	URL = URL.JoinPath("q/d/l/")
+	query := URL.Query()
+	query.Add("s", s.code)
+	query.Add("d1", start.Format("20060102"))
+	query.Add("d2", end.Format("20060102"))
+	query.Add("i", "d")
+	URL.RawQuery = query.Encode()
+
+	body, err := fetch(ctx, c.Client, c.Retry, c.Name(), symbol, URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// an unknown code is answered with 200 and a "No data" body
+	if !bytes.HasPrefix(body, []byte("Date,")) {
+		return nil, &FetchError{
+			Provider: c.Name(), Symbol: symbol, StatusCode: http.StatusOK, Kind: ErrSymbolNotFound,
+			Err: fmt.Errorf("%s", bytes.TrimSpace(body)),
+		}
+	}
+	stocks, err := ConvertStooqCSVToStock(bytes.NewReader(body), symbol, s.name, s.currency)
+	if err != nil {
+		return nil, &FetchError{
+			Provider: c.Name(), Symbol: symbol, StatusCode: http.StatusOK, Kind: ErrMalformedPayload, Err: err,
+		}
+	}
+	return stocks, nil
+}
+
+// ConvertStooqCSVToStock parses the "Date,Open,High,Low,Close,Volume" rows of
+// the Stooq CSV download. The name and the currency may be empty when they are
+// unknown.
+func ConvertStooqCSVToStock(r io.Reader, symbol, name, currency string) (*Stocks, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 || len(records[0]) < 5 || records[0][0] != "Date" {
+		return nil, fmt.Errorf("no data for %s", symbol)
+	}
+	stocks := make([]Stock, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if len(record) < 5 {
+			return nil, fmt.Errorf("malformed row %v", record)
+		}
+		timestamp, err := time.Parse(time.DateOnly, record[0])
+		if err != nil {
+			return nil, err
+		}
+		values := make([]float64, 4)
+		for i, field := range record[1:5] {
+			if values[i], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, err
+			}
+		}
+		var options []StockOption
+		if len(record) > 5 {
+			if volume, err := strconv.ParseInt(record[5], 10, 64); err == nil {
+				options = append(options, WithStockVolume(volume))
+			}
+		}
+		// the columns are ordered open, high, low, close
+		stock, err := NewStock(symbol, timestamp, values[0], values[3], values[1], values[2], options...)
+		if err != nil {
+			logger.Error("new stock error", "error", err)
+			continue
+		}
+		stocks = append(stocks, stock)
+	}
+	if len(stocks) == 0 {
+		return nil, fmt.Errorf("no data for %s", symbol)
+	}
+	latest := decimal.NewFromFloat(stocks[len(stocks)-1].Close)
+	previous := latest
+	if len(stocks) > 1 {
+		previous = decimal.NewFromFloat(stocks[len(stocks)-2].Close)
+	}
+	detail := NewSymbolDetail(symbol, name, name, currency, latest, previous)
+	return NewStocks(*detail, stocks)
+}
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/uptrace/bun"
+
+	"github.com/heyjun3/notify-stock/internal/calendar"
 )
 
 type SymbolDetail struct {
@@ -21,14 +24,88 @@ type SymbolDetail struct {
 	Volume        sql.NullInt64   `bun:"volume"`
 	MarketCap     sql.NullInt64   `bun:"market_cap"`
 	Currency      *Currency       `bun:"currency"`
+	// Exchange is empty when the provider does not tell where the symbol is
+	// listed.
+	Exchange calendar.Exchange `bun:"exchange,nullzero"`
+	// The metadata below is only known from Yahoo Finance.
+	ExchangeName     string         `bun:"exchange_name,nullzero"`
+	FullExchangeName string         `bun:"full_exchange_name,nullzero"`
+	InstrumentType   InstrumentType `bun:"instrument_type,nullzero"`
+	// Timezone is the IANA time zone of the exchange, e.g. "Asia/Tokyo".
+	Timezone         string              `bun:"timezone,nullzero"`
+	FiftyTwoWeekHigh decimal.NullDecimal `bun:"fifty_two_week_high,type:decimal"`
+	FiftyTwoWeekLow  decimal.NullDecimal `bun:"fifty_two_week_low,type:decimal"`
+	FirstTradeDate   sql.NullTime        `bun:"first_trade_date,type:timestamp"`
+}
+
+type InstrumentType string
+
+const (
+	InstrumentTypeEquity         InstrumentType = "equity"
+	InstrumentTypeETF            InstrumentType = "etf"
+	InstrumentTypeIndex          InstrumentType = "index"
+	InstrumentTypeMutualFund     InstrumentType = "mutualfund"
+	InstrumentTypeCurrency       InstrumentType = "currency"
+	InstrumentTypeCryptocurrency InstrumentType = "cryptocurrency"
+	InstrumentTypeFuture         InstrumentType = "future"
+)
+
+func (t InstrumentType) IsValid() bool {
+	switch t {
+	case InstrumentTypeEquity, InstrumentTypeETF, InstrumentTypeIndex, InstrumentTypeMutualFund,
+		InstrumentTypeCurrency, InstrumentTypeCryptocurrency, InstrumentTypeFuture:
+		return true
+	}
+	return false
+}
+
+// fxPlaces is the number of decimal digits FX rates are shown with, finer
+// than the minor unit of the quote currency.
+const fxPlaces = 4
+
+// IsFXPair reports whether the symbol is an exchange rate such as USDJPY=X,
+// whose price is not an amount of its currency.
+func (s *SymbolDetail) IsFXPair() bool {
+	return s.InstrumentType == InstrumentTypeCurrency || strings.HasSuffix(s.Symbol, "=X")
+}
+
+// pricePlaces returns the decimal digits the prices of the symbol are shown
+// with: those of its currency, or more for FX rates.
+func (s *SymbolDetail) pricePlaces() int32 {
+	switch {
+	case s.IsFXPair():
+		return fxPlaces
+	case s.Currency != nil:
+		return s.Currency.MinorUnits()
+	}
+	return 2
+}
+
+// roundPrices rounds the prices to the minor unit of the currency, the
+// precision the change is shown with. FX rates are kept as they are, since
+// the converted prices are only as precise as them.
+func (s *SymbolDetail) roundPrices() {
+	if s.IsFXPair() {
+		return
+	}
+	places := s.pricePlaces()
+	s.MarketPrice = s.MarketPrice.Round(places)
+	s.PreviousClose = s.PreviousClose.Round(places)
+	if s.FiftyTwoWeekLow.Valid && s.FiftyTwoWeekHigh.Valid {
+		s.FiftyTwoWeekLow.Decimal = s.FiftyTwoWeekLow.Decimal.Round(places)
+		s.FiftyTwoWeekHigh.Decimal = s.FiftyTwoWeekHigh.Decimal.Round(places)
+	}
 }
 
+// Change returns the change from the previous close, e.g. "+1.50", with
+// the decimal digits of the currency.
 func (s *SymbolDetail) Change() string {
-	change := s.MarketPrice.Sub(s.PreviousClose)
+	places := s.pricePlaces()
+	change := s.MarketPrice.Sub(s.PreviousClose).Round(places)
 	if change.IsPositive() {
-		return "+" + change.String()
+		return "+" + change.StringFixed(places)
 	}
-	return change.String()
+	return change.StringFixed(places)
 }
 func (s *SymbolDetail) ChangePercent() string {
 	p := s.MarketPrice.Sub(s.PreviousClose).Div(s.PreviousClose).
@@ -42,8 +119,78 @@ func (s *SymbolDetail) Key() string {
 	return s.Symbol
 }
 
+// ExchangeCalendar returns the calendar of the exchange of the symbol.
+func (s *SymbolDetail) ExchangeCalendar() (*calendar.Calendar, bool) {
+	c, err := calendar.For(s.Exchange)
+	return c, err == nil
+}
+
+// Calendar returns the trading days of the symbol, falling back to every
+// weekday when its exchange is unknown.
+func (s *SymbolDetail) Calendar() TradingCalendar {
+	if c, ok := s.ExchangeCalendar(); ok {
+		return c
+	}
+	return WeekdayCalendar{}
+}
+
 type SymbolDetailOption func(detail *SymbolDetail) *SymbolDetail
 
+func WithExchange(exchange calendar.Exchange) SymbolDetailOption {
+	return func(detail *SymbolDetail) *SymbolDetail {
+		detail.Exchange = exchange
+		return detail
+	}
+}
+
+// WithExchangeName sets the exchange names of Yahoo Finance, e.g. "NMS" and
+// "NasdaqGS".
+func WithExchangeName(name, fullName string) SymbolDetailOption {
+	return func(detail *SymbolDetail) *SymbolDetail {
+		detail.ExchangeName = name
+		detail.FullExchangeName = fullName
+		return detail
+	}
+}
+
+// WithInstrumentType sets the type of the instrument, ignoring the ones that
+// are not supported.
+func WithInstrumentType(instrumentType InstrumentType) SymbolDetailOption {
+	return func(detail *SymbolDetail) *SymbolDetail {
+		if instrumentType.IsValid() {
+			detail.InstrumentType = instrumentType
+		}
+		return detail
+	}
+}
+
+func WithTimezone(timezone string) SymbolDetailOption {
+	return func(detail *SymbolDetail) *SymbolDetail {
+		detail.Timezone = timezone
+		return detail
+	}
+}
+
+// WithFiftyTwoWeekRange sets the lowest and highest prices of the last 52
+// weeks, leaving them unknown when either is zero.
+func WithFiftyTwoWeekRange(low, high decimal.Decimal) SymbolDetailOption {
+	return func(detail *SymbolDetail) *SymbolDetail {
+		if low.IsZero() || high.IsZero() {
+			return detail
+		}
+		detail.FiftyTwoWeekLow = decimal.NewNullDecimal(low)
+		detail.FiftyTwoWeekHigh = decimal.NewNullDecimal(high)
+		return detail
+	}
+}
+
+func WithFirstTradeDate(date time.Time) SymbolDetailOption {
+	return func(detail *SymbolDetail) *SymbolDetail {
+		detail.FirstTradeDate = sql.NullTime{Time: date, Valid: true}
+		return detail
+	}
+}
+
 func WithVolume(volume int64) SymbolDetailOption {
 	return func(detail *SymbolDetail) *SymbolDetail {
 		detail.Volume = sql.NullInt64{Int64: volume, Valid: true}
@@ -57,24 +204,28 @@ func WithMarketCap(marketCap int64) SymbolDetailOption {
 	}
 }
 
+// NewSymbolDetail returns the detail of the symbol, leaving the currency
+// unknown when it is not an ISO 4217 code. The prices are rounded by the
+// currency, except the ones of FX pairs.
 func NewSymbolDetail(symbol, shortName, longName, currency string,
 	marketPrice, previousClose decimal.Decimal,
 	options ...SymbolDetailOption) *SymbolDetail {
-	cur, err := CurrencyString(currency)
-	if err != nil {
-		return nil
-	}
 	detail := &SymbolDetail{
 		Symbol:        symbol,
 		ShortName:     shortName,
 		LongName:      longName,
-		MarketPrice:   marketPrice.Round(2),
-		PreviousClose: previousClose.Round(2),
-		Currency:      &cur,
+		MarketPrice:   marketPrice,
+		PreviousClose: previousClose,
+	}
+	if cur, err := ParseCurrency(currency); err == nil {
+		detail.Currency = &cur
+	} else if currency != "" {
+		logger.Warn("unknown currency", "symbol", symbol, "currency", currency)
 	}
 	for _, option := range options {
 		option(detail)
 	}
+	detail.roundPrices()
 	return detail
 }
 
@@ -92,13 +243,23 @@ func (r *SymbolRepository) Save(ctx context.Context, details []SymbolDetail) err
 	_, err := r.db.NewInsert().Model(&details).
 		On("CONFLICT (symbol) DO UPDATE").
 		Set(strings.Join([]string{
-			"short_name = EXCLUDED.short_name",
-			"long_name = EXCLUDED.long_name",
+			// a fallback provider may not know the names, the volume,
+			// the market cap nor the currency
+			"short_name = COALESCE(NULLIF(EXCLUDED.short_name, ''), symbol_detail.short_name)",
+			"long_name = COALESCE(NULLIF(EXCLUDED.long_name, ''), symbol_detail.long_name)",
 			"market_price = EXCLUDED.market_price",
 			"previous_close = EXCLUDED.previous_close",
-			"volume = EXCLUDED.volume",
-			"market_cap = EXCLUDED.market_cap",
-			"currency = EXCLUDED.currency",
+			"volume = COALESCE(EXCLUDED.volume, symbol_detail.volume)",
+			"market_cap = COALESCE(EXCLUDED.market_cap, symbol_detail.market_cap)",
+			"currency = COALESCE(EXCLUDED.currency, symbol_detail.currency)",
+			"exchange = COALESCE(EXCLUDED.exchange, symbol_detail.exchange)",
+			"exchange_name = COALESCE(EXCLUDED.exchange_name, symbol_detail.exchange_name)",
+			"full_exchange_name = COALESCE(EXCLUDED.full_exchange_name, symbol_detail.full_exchange_name)",
+			"instrument_type = COALESCE(EXCLUDED.instrument_type, symbol_detail.instrument_type)",
+			"timezone = COALESCE(EXCLUDED.timezone, symbol_detail.timezone)",
+			"fifty_two_week_high = COALESCE(EXCLUDED.fifty_two_week_high, symbol_detail.fifty_two_week_high)",
+			"fifty_two_week_low = COALESCE(EXCLUDED.fifty_two_week_low, symbol_detail.fifty_two_week_low)",
+			"first_trade_date = COALESCE(EXCLUDED.first_trade_date, symbol_detail.first_trade_date)",
 		}, ",")).
 		Exec(ctx)
 	return err
@@ -132,6 +293,19 @@ func (r *SymbolRepository) GetBySymbols(
 	return details, nil
 }
 
+func (r *SymbolRepository) GetByInstrumentType(
+	ctx context.Context, instrumentType InstrumentType) ([]SymbolDetail, error) {
+	var details []SymbolDetail
+	err := r.db.NewSelect().Model(&details).
+		Where("instrument_type = ?", instrumentType).
+		Order("symbol ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
 func (r *SymbolRepository) GetAll(ctx context.Context) ([]SymbolDetail, error) {
 	var details []SymbolDetail
 	err := r.db.NewSelect().Model(&details).Scan(ctx)
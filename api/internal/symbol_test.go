@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
 	notify "github.com/heyjun3/notify-stock/internal"
+	"github.com/heyjun3/notify-stock/internal/calendar"
 )
 
 func TestSymbolDetail(t *testing.T) {
@@ -29,6 +31,47 @@ func TestSymbolDetail(t *testing.T) {
 			decimal.NewFromInt(800), decimal.NewFromInt(800))
 
 		assert.Equal(t, "0", detail.Change())
+
+		detail = notify.NewSymbolDetail("AAPL", "Apple", "Apple Inc.", "USD",
+			decimal.RequireFromString("201.5"), decimal.NewFromInt(200))
+
+		assert.Equal(t, "+1.50", detail.Change())
+
+		detail = notify.NewSymbolDetail("7203.T", "Toyota", "Toyota Motor", "JPY",
+			decimal.RequireFromString("2999.6"), decimal.NewFromInt(3000))
+
+		assert.Equal(t, "0", detail.Change())
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		detail := notify.NewSymbolDetail("VOD.L", "Vodafone", "Vodafone Group", "GBp",
+			decimal.NewFromInt(70), decimal.NewFromInt(69))
+
+		assert.NotNil(t, detail)
+		assert.Nil(t, detail.Currency)
+		assert.Equal(t, "+1.00", detail.Change())
+	})
+
+	t.Run("round prices by currency", func(t *testing.T) {
+		n225 := notify.NewSymbolDetail("^N225", "Nikkei 225", "Nikkei 225", "JPY",
+			decimal.RequireFromString("33763.184"), decimal.RequireFromString("33377.416"),
+			notify.WithFiftyTwoWeekRange(decimal.RequireFromString("25661.891"), decimal.RequireFromString("33853.461")))
+		assert.Equal(t, "33763", n225.MarketPrice.String())
+		assert.Equal(t, "33377", n225.PreviousClose.String())
+		assert.Equal(t, "25662", n225.FiftyTwoWeekLow.Decimal.String())
+		assert.Equal(t, "33853", n225.FiftyTwoWeekHigh.Decimal.String())
+
+		kwd := notify.NewSymbolDetail("KFH.KW", "KFH", "Kuwait Finance House", "KWD",
+			decimal.RequireFromString("0.7214"), decimal.RequireFromString("0.7186"))
+		assert.Equal(t, "0.721", kwd.MarketPrice.String())
+		assert.Equal(t, "+0.002", kwd.Change())
+
+		eurusd := notify.NewSymbolDetail("EURUSD=X", "EUR/USD", "EUR/USD", "USD",
+			decimal.RequireFromString("1.091346"), decimal.RequireFromString("1.094212"),
+			notify.WithInstrumentType(notify.InstrumentTypeCurrency))
+		assert.Equal(t, "1.091346", eurusd.MarketPrice.String())
+		assert.Equal(t, "1.094212", eurusd.PreviousClose.String())
+		assert.Equal(t, "-0.0029", eurusd.Change())
 	})
 
 	t.Run("calculate change percent", func(t *testing.T) {
@@ -145,4 +188,40 @@ func TestSymbolRepository(t *testing.T) {
 		})
 		assert.Greater(t, i, -1)
 	})
+
+	t.Run("keep metadata unknown to the provider", func(t *testing.T) {
+		firstTradeDate := time.Date(1965, 1, 5, 0, 0, 0, 0, time.UTC)
+		detail := notify.NewSymbolDetail("^N225", "Nikkei 225", "Nikkei 225", "JPY",
+			decimal.NewFromInt(1000), decimal.NewFromInt(900),
+			notify.WithExchange(calendar.TSE),
+			notify.WithExchangeName("OSA", "Osaka"),
+			notify.WithInstrumentType(notify.InstrumentTypeIndex),
+			notify.WithTimezone("Asia/Tokyo"),
+			notify.WithFiftyTwoWeekRange(decimal.NewFromInt(800), decimal.NewFromInt(1200)),
+			notify.WithFirstTradeDate(firstTradeDate))
+		assert.NoError(t, repo.Save(context.Background(), []notify.SymbolDetail{*detail}))
+		// Stooq tells neither the exchange nor the instrument
+		fallback := notify.NewSymbolDetail("^N225", "Nikkei 225", "Nikkei 225", "JPY",
+			decimal.NewFromInt(1100), decimal.NewFromInt(1000))
+		assert.NoError(t, repo.Save(context.Background(), []notify.SymbolDetail{*fallback}))
+
+		symbol, err := repo.Get(context.Background(), "^N225")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1100", symbol.MarketPrice.String())
+		assert.Equal(t, calendar.TSE, symbol.Exchange)
+		assert.Equal(t, "OSA", symbol.ExchangeName)
+		assert.Equal(t, "Osaka", symbol.FullExchangeName)
+		assert.Equal(t, notify.InstrumentTypeIndex, symbol.InstrumentType)
+		assert.Equal(t, "Asia/Tokyo", symbol.Timezone)
+		assert.Equal(t, "1200", symbol.FiftyTwoWeekHigh.Decimal.String())
+		assert.Equal(t, "800", symbol.FiftyTwoWeekLow.Decimal.String())
+		assert.True(t, firstTradeDate.Equal(symbol.FirstTradeDate.Time))
+
+		indices, err := repo.GetByInstrumentType(context.Background(), notify.InstrumentTypeIndex)
+
+		assert.NoError(t, err)
+		assert.Len(t, indices, 1)
+		assert.Equal(t, "^N225", indices[0].Symbol)
+	})
 }
@@ -0,0 +1,172 @@
+package notifystock
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+
+	"github.com/shopspring/decimal"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+// summaryTemplate is parsed with a placeholder "t" that is replaced by the
+// message catalog of the locale on rendering.
+var summaryTemplate = template.Must(
+	template.New("summary.html").
+		Funcs(template.FuncMap{"t": LocaleEN.T}).
+		ParseFS(templates, "templates/summary.html"),
+)
+
+// sparklineDays is the number of the latest closes drawn in the sparkline.
+const sparklineDays = 30
+
+type SummaryRow struct {
+	Name               string
+	Symbol             string
+	Close              string
+	Change             string
+	ChangePercent      string
+	Down               bool
+	MovingAverageLabel string
+	MovingAverage      string
+	Ratio              string
+	// Sparkline is a PNG image attached to the mail inline.
+	Sparkline []byte
+	// SparklineName is the name the sparkline is attached with, set on
+	// rendering.
+	SparklineName string
+}
+
+// SummaryRow returns the values of the summary table of the stocks.
+func (s *Stocks) SummaryRow(window MovingAverageWindow, locale Locale) (SummaryRow, error) {
+	avg, err := s.WindowAverage(window)
+	if err != nil {
+		return SummaryRow{}, err
+	}
+	closes := s.Closes()
+	latest := decimal.NewFromFloat(closes[len(closes)-1])
+	row := SummaryRow{
+		Name:               s.symbol.ShortName,
+		Symbol:             s.symbol.Symbol,
+		Close:              locale.FormatPrice(latest, s.symbol.Currency),
+		Change:             "-",
+		ChangePercent:      "-",
+		MovingAverageLabel: window.Label(locale),
+		MovingAverage:      locale.FormatPrice(avg, s.symbol.Currency),
+		Ratio:              fmt.Sprintf("%v%%", latest.Div(avg).Mul(decimal.New(100, 0)).RoundCeil(2)),
+		Sparkline:          Sparkline(closes[max(0, len(closes)-sparklineDays):], 160, 40),
+	}
+	if len(closes) > 1 {
+		previous := decimal.NewFromFloat(closes[len(closes)-2])
+		places := s.symbol.pricePlaces()
+		change := latest.Sub(previous).Round(places)
+		row.Change = signed(change, FormatNumber(change.Abs(), places))
+		percent := change.Div(previous).Mul(decimal.New(100, 0)).Round(2)
+		row.ChangePercent = signed(percent, percent.Abs().String()) + "%"
+		row.Down = change.IsNegative()
+	}
+	return row, nil
+}
+
+// signed prefixes the formatted absolute value with the sign of d.
+func signed(d decimal.Decimal, formatted string) string {
+	switch {
+	case d.IsPositive():
+		return "+" + formatted
+	case d.IsNegative():
+		return "-" + formatted
+	}
+	return formatted
+}
+
+// Sparkline draws values as a PNG line chart of width by height pixels, green
+// when the last value is not below the first one and red otherwise. It is
+// attached to the mail inline, since mail clients strip inline SVG.
+func Sparkline(values []float64, width, height int) []byte {
+	if len(values) < 2 {
+		return nil
+	}
+	const padding = 2
+	low, high := slices.Min(values), slices.Max(values)
+	span := high - low
+	if span == 0 {
+		span = 1
+	}
+	step := float64(width-2*padding) / float64(len(values)-1)
+	point := func(i int) (float64, float64) {
+		return padding + float64(i)*step, padding + (high-values[i])/span*float64(height-2*padding)
+	}
+	stroke := color.RGBA{R: 0x18, G: 0x80, B: 0x38, A: 0xff}
+	if values[len(values)-1] < values[0] {
+		stroke = color.RGBA{R: 0xd9, G: 0x30, B: 0x25, A: 0xff}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 1; i < len(values); i++ {
+		x0, y0 := point(i - 1)
+		x1, y1 := point(i)
+		drawLine(img, x0, y0, x1, y1, stroke)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		logger.Error("failed to encode sparkline", "error", err)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// drawLine plots the segment two pixels thick by sampling it every quarter
+// of a pixel.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Ceil(max(math.Abs(x1-x0), math.Abs(y1-y0))*4)) + 1
+	for i := range steps + 1 {
+		t := float64(i) / float64(steps)
+		x := int(math.Round(x0 + (x1-x0)*t))
+		y := int(math.Round(y0 + (y1-y0)*t))
+		img.SetRGBA(x, y, c)
+		img.SetRGBA(x, y+1, c)
+	}
+}
+
+// sparklineName returns the name the sparkline of the i-th row is attached
+// with.
+func sparklineName(i int) string {
+	return fmt.Sprintf("sparkline-%d.png", i)
+}
+
+// RenderSummaryHTML renders the summary mail and returns the sparklines of
+// the rows to attach inline with it.
+func RenderSummaryHTML(subject string, rows []SummaryRow, locale Locale) (string, []InlineImage, error) {
+	tmpl, err := summaryTemplate.Clone()
+	if err != nil {
+		return "", nil, err
+	}
+	tmpl.Funcs(template.FuncMap{"t": locale.T})
+	rows = slices.Clone(rows)
+	var images []InlineImage
+	for i := range rows {
+		if len(rows[i].Sparkline) == 0 {
+			continue
+		}
+		rows[i].SparklineName = sparklineName(i)
+		images = append(images, InlineImage{Name: rows[i].SparklineName, Data: rows[i].Sparkline})
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct {
+		Subject string
+		Rows    []SummaryRow
+	}{
+		Subject: subject,
+		Rows:    rows,
+	}); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), images, nil
+}
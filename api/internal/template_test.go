@@ -0,0 +1,112 @@
+package notifystock_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func TestSparkline(t *testing.T) {
+	decode := func(t *testing.T, data []byte) image.Image {
+		t.Helper()
+		img, err := png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		return img
+	}
+	green := color.RGBA{R: 0x18, G: 0x80, B: 0x38, A: 0xff}
+	red := color.RGBA{R: 0xd9, G: 0x30, B: 0x25, A: 0xff}
+
+	t.Run("draw rising line", func(t *testing.T) {
+		img := decode(t, notify.Sparkline([]float64{1, 2, 3}, 104, 24))
+
+		assert.Equal(t, image.Rect(0, 0, 104, 24), img.Bounds())
+		assert.Equal(t, green, color.RGBAModel.Convert(img.At(2, 22)))
+		assert.Equal(t, green, color.RGBAModel.Convert(img.At(52, 12)))
+		assert.Equal(t, green, color.RGBAModel.Convert(img.At(102, 2)))
+		assert.Equal(t, uint32(0), alpha(img.At(2, 2)))
+	})
+	t.Run("draw falling line in red", func(t *testing.T) {
+		img := decode(t, notify.Sparkline([]float64{3, 1}, 104, 24))
+
+		assert.Equal(t, red, color.RGBAModel.Convert(img.At(2, 2)))
+	})
+	t.Run("draw flat line", func(t *testing.T) {
+		img := decode(t, notify.Sparkline([]float64{5, 5}, 104, 24))
+
+		assert.Equal(t, green, color.RGBAModel.Convert(img.At(2, 2)))
+		assert.Equal(t, green, color.RGBAModel.Convert(img.At(102, 2)))
+	})
+	t.Run("skip single value", func(t *testing.T) {
+		assert.Empty(t, notify.Sparkline([]float64{1}, 104, 24))
+	})
+}
+
+func alpha(c color.Color) uint32 {
+	_, _, _, a := c.RGBA()
+	return a
+}
+
+func TestStocksSummaryRow(t *testing.T) {
+	symbol := notify.NewSymbolDetail("N225", "NIKKEI 225", "NIKKEI", "JPY", decimal.NewFromInt(4), decimal.NewFromInt(3))
+	closes := make([]float64, 0, 40)
+	for i := range 40 {
+		closes = append(closes, float64(40-i))
+	}
+	s, err := notify.NewStocks(*symbol, newDailyStocks(closes...))
+	assert.NoError(t, err)
+
+	row, err := s.SummaryRow(notify.MovingAverageWindow25Days, notify.LocaleEN)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "NIKKEI 225", row.Name)
+	assert.Equal(t, "¥1", row.Close)
+	assert.Equal(t, "-1", row.Change)
+	assert.Equal(t, "-50%", row.ChangePercent)
+	assert.True(t, row.Down)
+	assert.Equal(t, "25-Day", row.MovingAverageLabel)
+	img, err := png.DecodeConfig(bytes.NewReader(row.Sparkline))
+	assert.NoError(t, err)
+	assert.Equal(t, 160, img.Width)
+}
+
+func TestRenderSummaryHTML(t *testing.T) {
+	sparkline := notify.Sparkline([]float64{1, 2}, 104, 24)
+	html, images, err := notify.RenderSummaryHTML("Market Summary", []notify.SummaryRow{{
+		Name:               "<b>S&P 500</b>",
+		Symbol:             "^GSPC",
+		Close:              "6000 USD",
+		Change:             "+10",
+		ChangePercent:      "+0.17%",
+		MovingAverageLabel: "1-Year",
+		MovingAverage:      "5500 USD",
+		Ratio:              "109.09%",
+		Sparkline:          sparkline,
+	}, {
+		Name: "Nikkei 225",
+	}}, notify.LocaleEN)
+
+	assert.NoError(t, err)
+	assert.Contains(t, html, "&lt;b&gt;S&amp;P 500&lt;/b&gt;")
+	assert.Contains(t, html, "Closing Price to 1-Year Moving Average Ratio")
+	assert.Contains(t, html, `<img src="cid:sparkline-0.png"`)
+	assert.Equal(t, 1, strings.Count(html, "<img"))
+	assert.Equal(t, []notify.InlineImage{{Name: "sparkline-0.png", Data: sparkline}}, images)
+
+	html, _, err = notify.RenderSummaryHTML("マーケットサマリー", []notify.SummaryRow{{
+		Name:               "日経平均",
+		MovingAverageLabel: "1年",
+	}}, notify.LocaleJA)
+
+	assert.NoError(t, err)
+	assert.Contains(t, html, `<html lang="ja">`)
+	assert.Contains(t, html, "終値の1年移動平均比")
+	assert.Contains(t, html, "前日比")
+}
@@ -0,0 +1,149 @@
+package notifystock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// TrackedSymbol is a symbol whose prices are registered and which members can
+// be notified of.
+type TrackedSymbol struct {
+	bun.BaseModel `bun:"table:tracked_symbols"`
+
+	Symbol  string `bun:"symbol,type:text,pk"`
+	Enabled bool   `bun:"enabled,notnull"`
+	// AddedBy is the member who added the symbol, or null when it was added
+	// from the CLI.
+	AddedBy uuid.NullUUID `bun:"added_by,type:uuid"`
+	// Priority orders the symbols when they are fetched, the highest first.
+	Priority  int       `bun:"priority,notnull"`
+	CreatedAt time.Time `bun:"created_at,type:timestamp,notnull"`
+	UpdatedAt time.Time `bun:"updated_at,type:timestamp,notnull"`
+}
+
+// symbolPattern matches the tickers of Yahoo Finance, e.g. "AAPL", "BRK-B",
+// "7203.T", "^N225" and "USDJPY=X".
+var symbolPattern = regexp.MustCompile(`^\^?[A-Z0-9][A-Z0-9.\-]{0,14}(=[A-Z])?$`)
+
+func NewTrackedSymbol(symbol string, addedBy *uuid.UUID, priority int, now time.Time) (*TrackedSymbol, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, NewValidationError("Invalid symbol", "symbol is empty")
+	}
+	if !symbolPattern.MatchString(symbol) {
+		return nil, NewValidationError("Invalid symbol", fmt.Sprintf("symbol %q is not a ticker", symbol))
+	}
+	tracked := &TrackedSymbol{
+		Symbol:    symbol,
+		Enabled:   true,
+		Priority:  priority,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if addedBy != nil {
+		tracked.AddedBy = uuid.NullUUID{UUID: *addedBy, Valid: true}
+	}
+	return tracked, nil
+}
+
+type TrackedSymbolRepository struct {
+	db *bun.DB
+}
+
+func NewTrackedSymbolRepository(db *bun.DB) *TrackedSymbolRepository {
+	return &TrackedSymbolRepository{
+		db: db,
+	}
+}
+
+// Save adds the symbols, or updates whether they are enabled and their
+// priority, keeping who added them first.
+func (r *TrackedSymbolRepository) Save(ctx context.Context, symbols []*TrackedSymbol) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&symbols).
+		On("CONFLICT (symbol) DO UPDATE").
+		Set(strings.Join([]string{
+			"enabled = EXCLUDED.enabled",
+			"priority = EXCLUDED.priority",
+			"updated_at = EXCLUDED.updated_at",
+		}, ",")).
+		Exec(ctx)
+	return err
+}
+
+func (r *TrackedSymbolRepository) Get(ctx context.Context, symbol string) (*TrackedSymbol, error) {
+	var tracked TrackedSymbol
+	if err := r.db.NewSelect().
+		Model(&tracked).
+		Where("symbol = ?", symbol).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+	return &tracked, nil
+}
+
+// GetAll returns the symbols in the order they are fetched, including the
+// disabled ones when all is true.
+func (r *TrackedSymbolRepository) GetAll(ctx context.Context, all bool) ([]*TrackedSymbol, error) {
+	var symbols []*TrackedSymbol
+	query := r.db.NewSelect().
+		Model(&symbols).
+		Order("priority DESC", "symbol ASC")
+	if !all {
+		query = query.Where("enabled")
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+// EnabledSymbols returns the enabled symbols in the order they are fetched.
+func (r *TrackedSymbolRepository) EnabledSymbols(ctx context.Context) ([]string, error) {
+	tracked, err := r.GetAll(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		symbols = append(symbols, t.Symbol)
+	}
+	return symbols, nil
+}
+
+// CountAddedBy returns how many symbols the member has added.
+func (r *TrackedSymbolRepository) CountAddedBy(ctx context.Context, memberID uuid.UUID) (int, error) {
+	return r.db.NewSelect().
+		Model((*TrackedSymbol)(nil)).
+		Where("added_by = ?", memberID).
+		Count(ctx)
+}
+
+// Disable stops fetching the symbol. The symbol is kept, so that the
+// notifications of it can still be read.
+func (r *TrackedSymbolRepository) Disable(ctx context.Context, symbol string, now time.Time) error {
+	res, err := r.db.NewUpdate().
+		Model((*TrackedSymbol)(nil)).
+		Set("enabled = FALSE").
+		Set("updated_at = ?", now).
+		Where("symbol = ?", symbol).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return NewNotFoundError("Tracked symbol")
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package notifystock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+// trackSymbols adds the symbols to the registry.
+func trackSymbols(t *testing.T, db *bun.DB, symbols ...string) {
+	t.Helper()
+	tracked := make([]*notify.TrackedSymbol, 0, len(symbols))
+	for _, symbol := range symbols {
+		s, err := notify.NewTrackedSymbol(symbol, nil, 0, time.Now())
+		assert.NoError(t, err)
+		tracked = append(tracked, s)
+	}
+	assert.NoError(t, notify.NewTrackedSymbolRepository(db).Save(t.Context(), tracked))
+}
+
+func TestNewTrackedSymbol(t *testing.T) {
+	now := time.Now()
+
+	symbol, err := notify.NewTrackedSymbol(" ^N225 ", nil, 1, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "^N225", symbol.Symbol)
+	assert.True(t, symbol.Enabled)
+	assert.False(t, symbol.AddedBy.Valid)
+
+	_, err = notify.NewTrackedSymbol(" ", nil, 0, now)
+
+	assert.Error(t, err)
+
+	for _, symbol := range []string{"brk-b", "7203.T", "USDJPY=X", "BTC-USD"} {
+		_, err = notify.NewTrackedSymbol(symbol, nil, 0, now)
+		assert.NoError(t, err, symbol)
+	}
+	for _, symbol := range []string{"AAPL US", "^", "../AAPL", "A=XYZ"} {
+		_, err = notify.NewTrackedSymbol(symbol, nil, 0, now)
+		assert.Error(t, err, symbol)
+	}
+}
+
+func TestTrackedSymbolRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := notify.NewTrackedSymbolRepository(db)
+	member := createMember(t, notify.NewMemberRepository(db))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	n225, err := notify.NewTrackedSymbol("^N225", &member.ID, 0, now)
+	assert.NoError(t, err)
+	gspc, err := notify.NewTrackedSymbol("^GSPC", nil, 0, now)
+	assert.NoError(t, err)
+	aapl, err := notify.NewTrackedSymbol("AAPL", nil, 10, now)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save(ctx, []*notify.TrackedSymbol{n225, gspc, aapl}))
+
+	t.Run("enabled symbols by priority", func(t *testing.T) {
+		symbols, err := repo.EnabledSymbols(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"AAPL", "^GSPC", "^N225"}, symbols)
+	})
+
+	t.Run("keep who added the symbol", func(t *testing.T) {
+		again, err := notify.NewTrackedSymbol("^N225", nil, 5, now.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Save(ctx, []*notify.TrackedSymbol{again}))
+
+		saved, err := repo.Get(ctx, "^N225")
+
+		assert.NoError(t, err)
+		assert.Equal(t, member.ID, saved.AddedBy.UUID)
+		assert.Equal(t, 5, saved.Priority)
+		assert.True(t, now.Equal(saved.CreatedAt))
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		assert.NoError(t, repo.Disable(ctx, "^GSPC", now))
+
+		enabled, err := repo.GetAll(ctx, false)
+		assert.NoError(t, err)
+		all, err := repo.GetAll(ctx, true)
+		assert.NoError(t, err)
+
+		assert.Len(t, enabled, 2)
+		assert.Len(t, all, 3)
+		assert.Error(t, repo.Disable(ctx, "UNKNOWN", now))
+	})
+}
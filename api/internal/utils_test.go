@@ -15,8 +15,16 @@ func openDB(t *testing.T) *bun.DB {
 	db := notify.NewDB(dsn)
 	for _, table := range []any{
 		(*notify.Stock)(nil),
+		(*notify.IntradayStock)(nil),
+		(*notify.Dividend)(nil),
+		(*notify.Split)(nil),
+		(*notify.Alert)(nil),
+		(*notify.OutboxMessage)(nil),
+		(*notify.NotificationDelivery)(nil),
 		(*notify.Notification)(nil),
 		(*notify.SymbolDetail)(nil),
+		(*notify.TrackedSymbol)(nil),
+		(*notify.BackfillJob)(nil),
 		(*notify.Member)(nil),
 		(*notify.GoogleMember)(nil),
 	} {
@@ -0,0 +1,256 @@
+package notifystock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/heyjun3/notify-stock/internal/calendar"
+)
+
+type IssueKind string
+
+const (
+	// IssueGap is a run of trading days without a stored price.
+	IssueGap IssueKind = "gap"
+	// IssueOutlier is a day-over-day move further from the mean than the
+	// sigma of VerifyOption.
+	IssueOutlier IssueKind = "outlier"
+	// IssueInvalidRange is a bar whose high is below its low, or whose open
+	// or close is outside of them.
+	IssueInvalidRange IssueKind = "invalid_range"
+	// IssueNonPositive is a bar with a price of zero or below.
+	IssueNonPositive IssueKind = "non_positive"
+)
+
+// Issue is a problem in the stored prices of a symbol between Start and End,
+// both inclusive.
+type Issue struct {
+	Symbol string
+	Kind   IssueKind
+	Start  time.Time
+	End    time.Time
+	Detail string
+}
+
+func (i Issue) String() string {
+	period := i.Start.Format(time.DateOnly)
+	if !i.End.Equal(i.Start) {
+		period += " - " + i.End.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s\t%s\t%s\t%s", i.Symbol, i.Kind, period, i.Detail)
+}
+
+type VerifyOption struct {
+	// Calendar overrides the calendars of the exchanges of the symbols.
+	Calendar TradingCalendar
+	// Sigma is how many standard deviations of the daily returns make a move
+	// an outlier.
+	Sigma float64
+}
+
+func DefaultVerifyOption() VerifyOption {
+	return VerifyOption{
+		Sigma: 5,
+	}
+}
+
+// VerifyStocks returns the issues in the prices of the symbol. Gaps are looked
+// for from the first stored day, or start when it is later, to end, on every
+// weekday unless the option has a calendar. A warning is logged when the
+// period is not covered by the calendar.
+func VerifyStocks(symbol string, stocks []Stock, start, end time.Time, option VerifyOption) []Issue {
+	if option.Calendar == nil {
+		option.Calendar = WeekdayCalendar{}
+	}
+	if c, ok := option.Calendar.(*calendar.Calendar); ok && (!c.Covers(start) || !c.Covers(end)) {
+		from, to := c.Coverage()
+		logger.Warn("calendar does not cover the period, gaps may be reported on holidays outside of it",
+			"symbol", symbol, "exchange", c.Exchange(), "start", start, "end", end, "from", from, "to", to)
+	}
+	sorted := slices.SortedFunc(slices.Values(stocks), func(a, b Stock) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	var issues []Issue
+	for _, stock := range sorted {
+		day := stock.Timestamp
+		switch {
+		case stock.Open <= 0 || stock.Close <= 0 || stock.High <= 0 || stock.Low <= 0:
+			issues = append(issues, Issue{Symbol: symbol, Kind: IssueNonPositive, Start: day, End: day,
+				Detail: fmt.Sprintf("open: %v, close: %v, high: %v, low: %v", stock.Open, stock.Close, stock.High, stock.Low)})
+		case stock.High < stock.Low ||
+			stock.Open < stock.Low || stock.Open > stock.High ||
+			stock.Close < stock.Low || stock.Close > stock.High:
+			issues = append(issues, Issue{Symbol: symbol, Kind: IssueInvalidRange, Start: day, End: day,
+				Detail: fmt.Sprintf("open: %v, close: %v, high: %v, low: %v", stock.Open, stock.Close, stock.High, stock.Low)})
+		}
+	}
+	issues = append(issues, findOutliers(symbol, sorted, option.Sigma)...)
+	issues = append(issues, findGaps(symbol, sorted, start, end, option.Calendar)...)
+	slices.SortStableFunc(issues, func(a, b Issue) int {
+		return a.Start.Compare(b.Start)
+	})
+	return issues
+}
+
+func findOutliers(symbol string, stocks []Stock, sigma float64) []Issue {
+	type move struct {
+		from, to Stock
+		change   float64
+	}
+	moves := make([]move, 0, len(stocks))
+	for i := 1; i < len(stocks); i++ {
+		if stocks[i-1].Close <= 0 || stocks[i].Close <= 0 {
+			continue
+		}
+		moves = append(moves, move{
+			from: stocks[i-1], to: stocks[i],
+			change: stocks[i].Close/stocks[i-1].Close - 1,
+		})
+	}
+	if len(moves) < 3 || sigma <= 0 {
+		return nil
+	}
+	var sum float64
+	for _, m := range moves {
+		sum += m.change
+	}
+	mean := sum / float64(len(moves))
+	var squares float64
+	for _, m := range moves {
+		squares += (m.change - mean) * (m.change - mean)
+	}
+	std := math.Sqrt(squares / float64(len(moves)))
+	if std == 0 {
+		return nil
+	}
+	var issues []Issue
+	for _, m := range moves {
+		if z := (m.change - mean) / std; math.Abs(z) > sigma {
+			issues = append(issues, Issue{Symbol: symbol, Kind: IssueOutlier,
+				Start: m.from.Timestamp, End: m.to.Timestamp,
+				Detail: fmt.Sprintf("close %v -> %v (%+.2f%%, %.1f sigma)",
+					m.from.Close, m.to.Close, m.change*100, z)})
+		}
+	}
+	return issues
+}
+
+func findGaps(symbol string, stocks []Stock, start, end time.Time, calendar TradingCalendar) []Issue {
+	if len(stocks) == 0 {
+		return nil
+	}
+	stored := make(map[time.Time]bool, len(stocks))
+	for _, stock := range stocks {
+		stored[stock.Timestamp.UTC().Truncate(24*time.Hour)] = true
+	}
+	day := start
+	if stocks[0].Timestamp.After(start) {
+		day = stocks[0].Timestamp
+	}
+	day = day.UTC().Truncate(24 * time.Hour)
+	end = end.UTC().Truncate(24 * time.Hour)
+	var issues []Issue
+	var gap *Issue
+	for ; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !calendar.IsTradingDay(day) {
+			continue
+		}
+		if stored[day] {
+			if gap != nil {
+				issues = append(issues, *gap)
+				gap = nil
+			}
+			continue
+		}
+		if gap == nil {
+			gap = &Issue{Symbol: symbol, Kind: IssueGap, Start: day}
+		}
+		gap.End = day
+	}
+	if gap != nil {
+		issues = append(issues, *gap)
+	}
+	for i := range issues {
+		days := 0
+		for d := issues[i].Start; !d.After(issues[i].End); d = d.AddDate(0, 0, 1) {
+			if calendar.IsTradingDay(d) {
+				days++
+			}
+		}
+		issues[i].Detail = fmt.Sprintf("%d trading days missing", days)
+	}
+	return issues
+}
+
+type StockVerifier struct {
+	stockRepository  *StockRepository
+	symbolRepository *SymbolRepository
+	register         *StockRegister
+}
+
+func NewStockVerifier(
+	stockRepository *StockRepository,
+	symbolRepository *SymbolRepository,
+	register *StockRegister,
+) *StockVerifier {
+	return &StockVerifier{
+		stockRepository:  stockRepository,
+		symbolRepository: symbolRepository,
+		register:         register,
+	}
+}
+
+// Verify returns the issues in the stored prices of the symbols between start
+// and end. Gaps are looked for on the calendar of the exchange of each symbol
+// unless the option has one.
+func (v *StockVerifier) Verify(
+	ctx context.Context, symbols []string, start, end time.Time, option VerifyOption,
+) ([]Issue, error) {
+	stocks, err := v.stockRepository.GetStockByPeriodAndSymbols(ctx, symbols, start, end)
+	if err != nil {
+		return nil, err
+	}
+	details, err := v.symbolRepository.GetBySymbols(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	calendars := make(map[string]TradingCalendar, len(details))
+	for _, detail := range details {
+		calendars[detail.Symbol] = detail.Calendar()
+	}
+	var issues []Issue
+	for _, symbol := range symbols {
+		found, ok := stocks[symbol]
+		if !ok {
+			issues = append(issues, Issue{Symbol: symbol, Kind: IssueGap, Start: start, End: end,
+				Detail: "no prices stored"})
+			continue
+		}
+		symbolOption := option
+		if symbolOption.Calendar == nil {
+			symbolOption.Calendar = calendars[symbol]
+		}
+		issues = append(issues, VerifyStocks(symbol, found, start, end, symbolOption)...)
+	}
+	return issues, nil
+}
+
+// Repair fetches the prices of every issue again through the register,
+// leaving the symbol details as they are. The returned error joins a
+// SymbolError for every range that failed.
+func (v *StockVerifier) Repair(ctx context.Context, issues []Issue) error {
+	var errs []error
+	for _, issue := range issues {
+		// the chart API excludes the end of the period
+		if err := v.register.RegisterHistoryBySymbol(
+			ctx, issue.Symbol, issue.Start, issue.End.AddDate(0, 0, 1),
+		); err != nil {
+			errs = append(errs, &SymbolError{Symbol: issue.Symbol, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
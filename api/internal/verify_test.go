@@ -0,0 +1,114 @@
+package notifystock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	notify "github.com/heyjun3/notify-stock/internal"
+)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func bar(timestamp time.Time, price float64) notify.Stock {
+	return notify.Stock{
+		Symbol:    "^N225",
+		Timestamp: timestamp,
+		Open:      price,
+		Close:     price,
+		High:      price + 1,
+		Low:       price - 1,
+	}
+}
+
+func TestVerifyStocks(t *testing.T) {
+	t.Run("gaps skip weekends", func(t *testing.T) {
+		// Thu 4th, then nothing until Wed 10th
+		stocks := []notify.Stock{
+			bar(day(time.January, 4), 100),
+			bar(day(time.January, 10), 100),
+			bar(day(time.January, 11), 100),
+		}
+
+		issues := notify.VerifyStocks("^N225", stocks, day(time.January, 1), day(time.January, 12),
+			notify.DefaultVerifyOption())
+
+		assert.Len(t, issues, 2)
+		assert.Equal(t, notify.IssueGap, issues[0].Kind)
+		assert.True(t, day(time.January, 5).Equal(issues[0].Start))
+		assert.True(t, day(time.January, 9).Equal(issues[0].End))
+		assert.Equal(t, "3 trading days missing", issues[0].Detail)
+		assert.Equal(t, notify.IssueGap, issues[1].Kind)
+		assert.True(t, day(time.January, 12).Equal(issues[1].Start))
+		assert.True(t, day(time.January, 12).Equal(issues[1].End))
+	})
+	t.Run("outlier", func(t *testing.T) {
+		var stocks []notify.Stock
+		price := 100.0
+		// half a year of small moves, so that a single jump stands out
+		for d := day(time.January, 1); d.Before(day(time.July, 1)); d = d.AddDate(0, 0, 1) {
+			if !(notify.WeekdayCalendar{}).IsTradingDay(d) {
+				continue
+			}
+			if d.Day()%2 == 0 {
+				price += 1
+			} else {
+				price -= 1
+			}
+			if d.Equal(day(time.March, 15)) {
+				price *= 10
+			}
+			stocks = append(stocks, bar(d, price))
+		}
+
+		issues := notify.VerifyStocks("^N225", stocks, day(time.January, 1), day(time.June, 28),
+			notify.DefaultVerifyOption())
+
+		assert.Len(t, issues, 1)
+		assert.Equal(t, notify.IssueOutlier, issues[0].Kind)
+		assert.True(t, day(time.March, 14).Equal(issues[0].Start))
+		assert.True(t, day(time.March, 15).Equal(issues[0].End))
+	})
+	t.Run("invalid bars", func(t *testing.T) {
+		inverted := bar(day(time.January, 9), 100)
+		inverted.High, inverted.Low = inverted.Low, inverted.High
+		zero := bar(day(time.January, 10), 100)
+		zero.Open = 0
+		stocks := []notify.Stock{
+			bar(day(time.January, 8), 100),
+			inverted,
+			zero,
+		}
+
+		issues := notify.VerifyStocks("^N225", stocks, day(time.January, 8), day(time.January, 10),
+			notify.DefaultVerifyOption())
+
+		assert.Len(t, issues, 2)
+		assert.Equal(t, notify.IssueInvalidRange, issues[0].Kind)
+		assert.True(t, day(time.January, 9).Equal(issues[0].Start))
+		assert.Equal(t, notify.IssueNonPositive, issues[1].Kind)
+		assert.True(t, day(time.January, 10).Equal(issues[1].Start))
+	})
+	t.Run("no issues", func(t *testing.T) {
+		stocks := []notify.Stock{
+			bar(day(time.January, 11), 101),
+			bar(day(time.January, 12), 102),
+			bar(day(time.January, 15), 103),
+		}
+
+		issues := notify.VerifyStocks("^N225", stocks, day(time.January, 11), day(time.January, 15),
+			notify.DefaultVerifyOption())
+
+		assert.Empty(t, issues)
+	})
+}
+
+func TestIssueString(t *testing.T) {
+	issue := notify.Issue{Symbol: "^N225", Kind: notify.IssueGap,
+		Start: day(time.January, 5), End: day(time.January, 9), Detail: "3 trading days missing"}
+
+	assert.Equal(t, "^N225\tgap\t2024-01-05 - 2024-01-09\t3 trading days missing", issue.String())
+}
@@ -10,11 +10,17 @@ import (
 	"github.com/uptrace/bun"
 )
 
-func InitStockRegister(db *bun.DB, client HTTPClientInterface) *StockRegister {
+func InitStockRegister(
+	db *bun.DB,
+	client HTTPClientInterface,
+	option StockRegisterOption,
+) *StockRegister {
 	wire.Build(
-		NewFinanceClient,
+		NewMarketDataProvider,
 		NewStockRepository,
 		NewSymbolRepository,
+		NewCorporateActionRepository,
+		NewTrackedSymbolRepository,
 		NewStockRegister,
 	)
 	return &StockRegister{}
@@ -30,18 +36,75 @@ func InitSymbolRepository(db *bun.DB) *SymbolRepository {
 func InitStockNotifier(
 	ctx context.Context,
 	db *bun.DB,
-	config MailGunClientConfig,
 ) (*StockNotifier, error) {
 	wire.Build(
-		NewMailGunClient,
 		NewStockRepository,
 		NewSymbolRepository,
+		NewMarketSummaryGenerator,
+		NewOutboxRepository,
 		NewStockNotifier,
-		wire.Bind(new(MailService), new(*MailGunClient)),
 	)
 	return &StockNotifier{}, nil
 }
 
+func InitNotificationDispatcher(
+	ctx context.Context,
+	db *bun.DB,
+) (*NotificationDispatcher, error) {
+	wire.Build(
+		NewStockRepository,
+		NewSymbolRepository,
+		NewMarketSummaryGenerator,
+		NewOutboxRepository,
+		NewNotificationRepository,
+		NewMemberRepository,
+		NewNotificationDeliveryRepository,
+		NewNotificationDispatcher,
+	)
+	return &NotificationDispatcher{}, nil
+}
+
+func InitOutboxWorker(
+	ctx context.Context,
+	db *bun.DB,
+	config MailGunClientConfig,
+	client HTTPClientInterface,
+	webhookOption WebhookChannelOption,
+	option OutboxWorkerOption,
+) (*OutboxWorker, error) {
+	wire.Build(
+		NewMailGunClient,
+		NewChannels,
+		NewOutboxRepository,
+		NewNotificationDeliveryRepository,
+		NewOutboxWorker,
+		wire.Bind(new(MailService), new(*MailGunClient)),
+	)
+	return &OutboxWorker{}, nil
+}
+
+func InitAlertEvaluator(
+	ctx context.Context,
+	db *bun.DB,
+) (*AlertEvaluator, error) {
+	wire.Build(
+		NewAlertRepository,
+		NewStockRepository,
+		NewSymbolRepository,
+		NewMemberRepository,
+		NewOutboxRepository,
+		NewAlertEvaluator,
+	)
+	return &AlertEvaluator{}, nil
+}
+
+func InitTrackedSymbolRepository(db *bun.DB) *TrackedSymbolRepository {
+	wire.Build(
+		NewTrackedSymbolRepository,
+	)
+	return &TrackedSymbolRepository{}
+}
+
 func InitStockRepository(db *bun.DB) *StockRepository {
 	wire.Build(
 		NewStockRepository,
@@ -56,6 +119,13 @@ func InitNotificationRepository(db *bun.DB) *NotificationRepository {
 	return &NotificationRepository{}
 }
 
+func InitNotificationDeliveryRepository(db *bun.DB) *NotificationDeliveryRepository {
+	wire.Build(
+		NewNotificationDeliveryRepository,
+	)
+	return &NotificationDeliveryRepository{}
+}
+
 func InitAuthHandler(sessions *Sessions, db *bun.DB, client http.Client, option GoogleClientOption) *AuthHandler {
 	wire.Build(
 		NewGoogleClient,
@@ -69,7 +139,133 @@ func InitNotificationCreator(db *bun.DB) *NotificationCreator {
 	wire.Build(
 		NewNotificationRepository,
 		NewSymbolRepository,
+		NewTrackedSymbolRepository,
+		NewMemberRepository,
 		NewNotificationCreator,
 	)
 	return &NotificationCreator{}
 }
+
+func InitAlertRepository(db *bun.DB) *AlertRepository {
+	wire.Build(
+		NewAlertRepository,
+	)
+	return &AlertRepository{}
+}
+
+func InitAlertCreator(db *bun.DB) *AlertCreator {
+	wire.Build(
+		NewAlertRepository,
+		NewSymbolRepository,
+		NewAlertCreator,
+	)
+	return &AlertCreator{}
+}
+
+func InitIndicatorService(db *bun.DB) *IndicatorService {
+	wire.Build(
+		NewStockRepository,
+		NewIndicatorService,
+	)
+	return &IndicatorService{}
+}
+
+func InitMemberRepository(db *bun.DB) *MemberRepository {
+	wire.Build(
+		NewMemberRepository,
+	)
+	return &MemberRepository{}
+}
+
+func InitIntradayRegister(db *bun.DB, client HTTPClientInterface) *IntradayRegister {
+	wire.Build(
+		NewIntradayProvider,
+		NewIntradayRepository,
+		NewIntradayRegister,
+	)
+	return &IntradayRegister{}
+}
+
+func InitIntradayRepository(db *bun.DB) *IntradayRepository {
+	wire.Build(
+		NewIntradayRepository,
+	)
+	return &IntradayRepository{}
+}
+
+func InitCorporateActionRepository(db *bun.DB) *CorporateActionRepository {
+	wire.Build(
+		NewCorporateActionRepository,
+	)
+	return &CorporateActionRepository{}
+}
+
+func InitStockVerifier(
+	db *bun.DB,
+	client HTTPClientInterface,
+	option StockRegisterOption,
+) *StockVerifier {
+	wire.Build(
+		NewMarketDataProvider,
+		NewStockRepository,
+		NewSymbolRepository,
+		NewCorporateActionRepository,
+		NewTrackedSymbolRepository,
+		NewStockRegister,
+		NewStockVerifier,
+	)
+	return &StockVerifier{}
+}
+
+func InitSymbolTracker(
+	db *bun.DB,
+	client HTTPClientInterface,
+	option StockRegisterOption,
+	trackerOption SymbolTrackerOption,
+) *SymbolTracker {
+	wire.Build(
+		NewSymbolSearcher,
+		NewMarketDataProvider,
+		NewStockRepository,
+		NewSymbolRepository,
+		NewCorporateActionRepository,
+		NewTrackedSymbolRepository,
+		NewStockRegister,
+		NewSymbolTracker,
+	)
+	return &SymbolTracker{}
+}
+
+func InitBackfiller(
+	db *bun.DB,
+	client HTTPClientInterface,
+	registerOption StockRegisterOption,
+	option BackfillOption,
+) *Backfiller {
+	wire.Build(
+		NewMarketDataProvider,
+		NewStockRepository,
+		NewSymbolRepository,
+		NewCorporateActionRepository,
+		NewTrackedSymbolRepository,
+		NewStockRegister,
+		NewBackfillJobRepository,
+		NewBackfiller,
+	)
+	return &Backfiller{}
+}
+
+func InitBackfillJobRepository(db *bun.DB) *BackfillJobRepository {
+	wire.Build(
+		NewBackfillJobRepository,
+	)
+	return &BackfillJobRepository{}
+}
+
+func InitCurrencyConverter(db *bun.DB) *CurrencyConverter {
+	wire.Build(
+		NewStockRepository,
+		NewCurrencyConverter,
+	)
+	return &CurrencyConverter{}
+}
@@ -14,11 +14,13 @@ import (
 
 // Injectors from wire.go:
 
-func InitStockRegister(db *bun.DB, client HTTPClientInterface) *StockRegister {
-	financeClient := NewFinanceClient(client)
+func InitStockRegister(db *bun.DB, client HTTPClientInterface, option StockRegisterOption) *StockRegister {
+	marketDataProvider := NewMarketDataProvider(client)
 	stockRepository := NewStockRepository(db)
 	symbolRepository := NewSymbolRepository(db)
-	stockRegister := NewStockRegister(financeClient, stockRepository, symbolRepository)
+	corporateActionRepository := NewCorporateActionRepository(db)
+	trackedSymbolRepository := NewTrackedSymbolRepository(db)
+	stockRegister := NewStockRegister(marketDataProvider, stockRepository, symbolRepository, corporateActionRepository, trackedSymbolRepository, option)
 	return stockRegister
 }
 
@@ -27,14 +29,51 @@ func InitSymbolRepository(db *bun.DB) *SymbolRepository {
 	return symbolRepository
 }
 
-func InitStockNotifier(ctx context.Context, db *bun.DB, config MailGunClientConfig) (*StockNotifier, error) {
-	mailGunClient := NewMailGunClient(config)
+func InitStockNotifier(ctx context.Context, db *bun.DB) (*StockNotifier, error) {
 	stockRepository := NewStockRepository(db)
 	symbolRepository := NewSymbolRepository(db)
-	stockNotifier := NewStockNotifier(mailGunClient, stockRepository, symbolRepository)
+	marketSummaryGenerator := NewMarketSummaryGenerator(stockRepository, symbolRepository)
+	outboxRepository := NewOutboxRepository(db)
+	stockNotifier := NewStockNotifier(marketSummaryGenerator, outboxRepository, symbolRepository)
 	return stockNotifier, nil
 }
 
+func InitNotificationDispatcher(ctx context.Context, db *bun.DB) (*NotificationDispatcher, error) {
+	stockRepository := NewStockRepository(db)
+	symbolRepository := NewSymbolRepository(db)
+	marketSummaryGenerator := NewMarketSummaryGenerator(stockRepository, symbolRepository)
+	outboxRepository := NewOutboxRepository(db)
+	notificationRepository := NewNotificationRepository(db)
+	memberRepository := NewMemberRepository(db)
+	notificationDeliveryRepository := NewNotificationDeliveryRepository(db)
+	notificationDispatcher := NewNotificationDispatcher(marketSummaryGenerator, outboxRepository, notificationRepository, memberRepository, notificationDeliveryRepository, symbolRepository)
+	return notificationDispatcher, nil
+}
+
+func InitOutboxWorker(ctx context.Context, db *bun.DB, config MailGunClientConfig, client HTTPClientInterface, webhookOption WebhookChannelOption, option OutboxWorkerOption) (*OutboxWorker, error) {
+	mailGunClient := NewMailGunClient(config)
+	channels := NewChannels(mailGunClient, client, webhookOption)
+	outboxRepository := NewOutboxRepository(db)
+	notificationDeliveryRepository := NewNotificationDeliveryRepository(db)
+	outboxWorker := NewOutboxWorker(channels, outboxRepository, notificationDeliveryRepository, option)
+	return outboxWorker, nil
+}
+
+func InitAlertEvaluator(ctx context.Context, db *bun.DB) (*AlertEvaluator, error) {
+	alertRepository := NewAlertRepository(db)
+	stockRepository := NewStockRepository(db)
+	symbolRepository := NewSymbolRepository(db)
+	memberRepository := NewMemberRepository(db)
+	outboxRepository := NewOutboxRepository(db)
+	alertEvaluator := NewAlertEvaluator(alertRepository, stockRepository, symbolRepository, memberRepository, outboxRepository)
+	return alertEvaluator, nil
+}
+
+func InitTrackedSymbolRepository(db *bun.DB) *TrackedSymbolRepository {
+	trackedSymbolRepository := NewTrackedSymbolRepository(db)
+	return trackedSymbolRepository
+}
+
 func InitStockRepository(db *bun.DB) *StockRepository {
 	stockRepository := NewStockRepository(db)
 	return stockRepository
@@ -45,6 +84,11 @@ func InitNotificationRepository(db *bun.DB) *NotificationRepository {
 	return notificationRepository
 }
 
+func InitNotificationDeliveryRepository(db *bun.DB) *NotificationDeliveryRepository {
+	notificationDeliveryRepository := NewNotificationDeliveryRepository(db)
+	return notificationDeliveryRepository
+}
+
 func InitAuthHandler(sessions *Sessions, db *bun.DB, client http.Client, option GoogleClientOption) *AuthHandler {
 	googleClient := NewGoogleClient(client, option)
 	memberRepository := NewMemberRepository(db)
@@ -55,6 +99,94 @@ func InitAuthHandler(sessions *Sessions, db *bun.DB, client http.Client, option
 func InitNotificationCreator(db *bun.DB) *NotificationCreator {
 	notificationRepository := NewNotificationRepository(db)
 	symbolRepository := NewSymbolRepository(db)
-	notificationCreator := NewNotificationCreator(notificationRepository, symbolRepository)
+	trackedSymbolRepository := NewTrackedSymbolRepository(db)
+	memberRepository := NewMemberRepository(db)
+	notificationCreator := NewNotificationCreator(notificationRepository, symbolRepository, trackedSymbolRepository, memberRepository)
 	return notificationCreator
 }
+
+func InitAlertRepository(db *bun.DB) *AlertRepository {
+	alertRepository := NewAlertRepository(db)
+	return alertRepository
+}
+
+func InitAlertCreator(db *bun.DB) *AlertCreator {
+	alertRepository := NewAlertRepository(db)
+	symbolRepository := NewSymbolRepository(db)
+	alertCreator := NewAlertCreator(alertRepository, symbolRepository)
+	return alertCreator
+}
+
+func InitIndicatorService(db *bun.DB) *IndicatorService {
+	stockRepository := NewStockRepository(db)
+	indicatorService := NewIndicatorService(stockRepository)
+	return indicatorService
+}
+
+func InitMemberRepository(db *bun.DB) *MemberRepository {
+	memberRepository := NewMemberRepository(db)
+	return memberRepository
+}
+
+func InitIntradayRegister(db *bun.DB, client HTTPClientInterface) *IntradayRegister {
+	intradayProvider := NewIntradayProvider(client)
+	intradayRepository := NewIntradayRepository(db)
+	intradayRegister := NewIntradayRegister(intradayProvider, intradayRepository)
+	return intradayRegister
+}
+
+func InitIntradayRepository(db *bun.DB) *IntradayRepository {
+	intradayRepository := NewIntradayRepository(db)
+	return intradayRepository
+}
+
+func InitCorporateActionRepository(db *bun.DB) *CorporateActionRepository {
+	corporateActionRepository := NewCorporateActionRepository(db)
+	return corporateActionRepository
+}
+
+func InitStockVerifier(db *bun.DB, client HTTPClientInterface, option StockRegisterOption) *StockVerifier {
+	stockRepository := NewStockRepository(db)
+	symbolRepository := NewSymbolRepository(db)
+	marketDataProvider := NewMarketDataProvider(client)
+	corporateActionRepository := NewCorporateActionRepository(db)
+	trackedSymbolRepository := NewTrackedSymbolRepository(db)
+	stockRegister := NewStockRegister(marketDataProvider, stockRepository, symbolRepository, corporateActionRepository, trackedSymbolRepository, option)
+	stockVerifier := NewStockVerifier(stockRepository, symbolRepository, stockRegister)
+	return stockVerifier
+}
+
+func InitSymbolTracker(db *bun.DB, client HTTPClientInterface, option StockRegisterOption, trackerOption SymbolTrackerOption) *SymbolTracker {
+	symbolSearcher := NewSymbolSearcher(client)
+	marketDataProvider := NewMarketDataProvider(client)
+	stockRepository := NewStockRepository(db)
+	symbolRepository := NewSymbolRepository(db)
+	corporateActionRepository := NewCorporateActionRepository(db)
+	trackedSymbolRepository := NewTrackedSymbolRepository(db)
+	stockRegister := NewStockRegister(marketDataProvider, stockRepository, symbolRepository, corporateActionRepository, trackedSymbolRepository, option)
+	symbolTracker := NewSymbolTracker(symbolSearcher, stockRegister, symbolRepository, trackedSymbolRepository, trackerOption)
+	return symbolTracker
+}
+
+func InitBackfiller(db *bun.DB, client HTTPClientInterface, registerOption StockRegisterOption, option BackfillOption) *Backfiller {
+	marketDataProvider := NewMarketDataProvider(client)
+	stockRepository := NewStockRepository(db)
+	symbolRepository := NewSymbolRepository(db)
+	corporateActionRepository := NewCorporateActionRepository(db)
+	trackedSymbolRepository := NewTrackedSymbolRepository(db)
+	stockRegister := NewStockRegister(marketDataProvider, stockRepository, symbolRepository, corporateActionRepository, trackedSymbolRepository, registerOption)
+	backfillJobRepository := NewBackfillJobRepository(db)
+	backfiller := NewBackfiller(stockRegister, symbolRepository, backfillJobRepository, option)
+	return backfiller
+}
+
+func InitBackfillJobRepository(db *bun.DB) *BackfillJobRepository {
+	backfillJobRepository := NewBackfillJobRepository(db)
+	return backfillJobRepository
+}
+
+func InitCurrencyConverter(db *bun.DB) *CurrencyConverter {
+	stockRepository := NewStockRepository(db)
+	currencyConverter := NewCurrencyConverter(stockRepository)
+	return currencyConverter
+}
@@ -61,3 +61,195 @@ CREATE TABLE IF NOT EXISTS
         FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE,
         FOREIGN KEY (symbol) REFERENCES symbols (symbol) ON DELETE CASCADE
     );
+
+CREATE TABLE IF NOT EXISTS
+    notification_deliveries (
+        id UUID PRIMARY KEY,
+        notification_id UUID NOT NULL,
+        scheduled_at TIMESTAMP NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+        UNIQUE (notification_id, scheduled_at),
+        FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE
+    );
+
+ALTER TABLE notification_deliveries
+ADD COLUMN channel TEXT NOT NULL DEFAULT 'email',
+ADD COLUMN status TEXT NOT NULL DEFAULT 'sent',
+ADD COLUMN response TEXT,
+ADD COLUMN attempts INTEGER NOT NULL DEFAULT 1,
+ADD COLUMN error TEXT,
+ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+ALTER TABLE notification_deliveries
+DROP CONSTRAINT notification_deliveries_notification_id_scheduled_at_key,
+ADD UNIQUE (notification_id, scheduled_at, channel);
+
+CREATE TABLE IF NOT EXISTS
+    mail_outbox (
+        id UUID PRIMARY KEY,
+        delivery_id UUID,
+        from_address TEXT NOT NULL,
+        to_address TEXT NOT NULL,
+        subject TEXT NOT NULL,
+        TEXT TEXT NOT NULL,
+        status TEXT NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at TIMESTAMP NOT NULL,
+        last_error TEXT,
+        created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+        updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+        FOREIGN KEY (delivery_id) REFERENCES notification_deliveries (id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS mail_outbox_due_idx ON mail_outbox (next_attempt_at)
+WHERE
+    status = 'pending';
+
+CREATE TABLE IF NOT EXISTS
+    notification_channels (
+        id UUID PRIMARY KEY,
+        notification_id UUID NOT NULL,
+        kind TEXT NOT NULL,
+        destination TEXT NOT NULL DEFAULT '',
+        UNIQUE (notification_id, kind),
+        FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE
+    );
+
+ALTER TABLE mail_outbox
+ADD COLUMN channel TEXT NOT NULL DEFAULT 'email';
+
+CREATE TABLE IF NOT EXISTS
+    alerts (
+        id UUID PRIMARY KEY,
+        member_id UUID NOT NULL,
+        symbol TEXT NOT NULL,
+        condition TEXT NOT NULL,
+        threshold DECIMAL NOT NULL,
+        cooldown_minutes INTEGER NOT NULL DEFAULT 0,
+        paused BOOLEAN NOT NULL DEFAULT FALSE,
+        last_triggered_at TIMESTAMP,
+        created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+        FOREIGN KEY (member_id) REFERENCES members (id) ON DELETE CASCADE,
+        FOREIGN KEY (symbol) REFERENCES symbols (symbol) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS alerts_symbol_idx ON alerts (symbol)
+WHERE
+    paused = FALSE;
+
+ALTER TABLE alerts
+ADD COLUMN upper_threshold DECIMAL NOT NULL DEFAULT 0,
+ADD COLUMN short_window INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN long_window INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE notifications
+ADD COLUMN moving_average_window TEXT NOT NULL DEFAULT 'months_12';
+
+ALTER TABLE mail_outbox
+ADD COLUMN html TEXT;
+
+ALTER TABLE members
+ADD COLUMN locale TEXT NOT NULL DEFAULT 'ja';
+
+CREATE TABLE IF NOT EXISTS
+    intraday_stocks (
+        symbol TEXT,
+        bar_interval TEXT,
+        TIMESTAMP TIMESTAMP,
+        open DECIMAL NOT NULL,
+        CLOSE DECIMAL NOT NULL,
+        high DECIMAL NOT NULL,
+        low DECIMAL NOT NULL,
+        PRIMARY KEY (symbol, bar_interval, TIMESTAMP)
+    );
+
+CREATE INDEX IF NOT EXISTS intraday_stocks_interval_timestamp_idx ON intraday_stocks (bar_interval, TIMESTAMP);
+
+ALTER TABLE stocks
+ADD COLUMN volume BIGINT,
+ADD COLUMN adj_close DECIMAL;
+
+CREATE TABLE IF NOT EXISTS
+    dividends (
+        symbol TEXT,
+        ex_date TIMESTAMP,
+        amount DECIMAL NOT NULL,
+        PRIMARY KEY (symbol, ex_date)
+    );
+
+CREATE TABLE IF NOT EXISTS
+    splits (
+        symbol TEXT,
+        date TIMESTAMP,
+        numerator BIGINT NOT NULL,
+        denominator BIGINT NOT NULL,
+        adjusted_at TIMESTAMP,
+        PRIMARY KEY (symbol, date)
+    );
+
+ALTER TABLE symbols
+ADD COLUMN exchange TEXT;
+
+ALTER TABLE symbols
+ADD COLUMN exchange_name TEXT,
+ADD COLUMN full_exchange_name TEXT,
+ADD COLUMN instrument_type TEXT,
+ADD COLUMN timezone TEXT,
+ADD COLUMN fifty_two_week_high DECIMAL,
+ADD COLUMN fifty_two_week_low DECIMAL,
+ADD COLUMN first_trade_date TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS
+    tracked_symbols (
+        symbol TEXT PRIMARY KEY,
+        enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        added_by UUID,
+        priority INTEGER NOT NULL DEFAULT 0,
+        created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+        updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+        FOREIGN KEY (added_by) REFERENCES members (id) ON DELETE SET NULL
+    );
+
+INSERT INTO
+    tracked_symbols (symbol)
+VALUES
+    ('^N225'),
+    ('^GSPC'),
+    ('^DJI'),
+    ('^IXIC'),
+    ('^XDN')
+ON CONFLICT (symbol) DO NOTHING;
+
+ALTER TABLE members
+ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS
+    backfill_jobs (
+        id UUID PRIMARY KEY,
+        symbol TEXT NOT NULL,
+        start_date TIMESTAMP NOT NULL,
+        end_date TIMESTAMP NOT NULL,
+        cursor_date TIMESTAMP NOT NULL,
+        status TEXT NOT NULL,
+        chunks INTEGER NOT NULL DEFAULT 0,
+        error TEXT,
+        created_at TIMESTAMP NOT NULL,
+        updated_at TIMESTAMP NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS backfill_jobs_unfinished_idx ON backfill_jobs (symbol, start_date)
+WHERE
+    status <> 'done';
+
+INSERT INTO
+    tracked_symbols (symbol)
+VALUES
+    ('USDJPY=X'),
+    ('EURUSD=X')
+ON CONFLICT (symbol) DO NOTHING;
+
+ALTER TABLE stocks
+ADD COLUMN fetched_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+ALTER TABLE mail_outbox
+ADD COLUMN inline_images JSONB;